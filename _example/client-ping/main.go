@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+func main() {
+	// Create a Client which can be reused for many servers
+	client := mcpinger.NewClient(mcpinger.WithTimeout(10 * time.Second))
+
+	for _, host := range []string{"mc.hypixel.net", "mc.herobone.de"} {
+		// Get server info
+		info, err := client.Ping(context.Background(), host, 25565)
+
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		// Print server info
+		fmt.Printf("%s: %d/%d online, version %s\n", host, info.Players.Online, info.Players.Max, info.Version.Name)
+	}
+}
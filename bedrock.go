@@ -0,0 +1,190 @@
+package mcpinger
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBedrockPort = 19132 // Default port of Bedrock Edition servers
+
+	// Interval between resending the unconnected ping, as UDP packets may be lost
+	bedrockResendInterval = 500 * time.Millisecond
+
+	raknetUnconnectedPing = 0x01
+	raknetUnconnectedPong = 0x1C
+)
+
+// Marks offline RakNet messages
+var raknetMagic = []byte{0x00, 0xFF, 0xFF, 0x00, 0xFE, 0xFE, 0xFE, 0xFE, 0xFD, 0xFD, 0xFD, 0xFD, 0x12, 0x34, 0x56, 0x78}
+
+// BedrockInfo is the server info of a Bedrock Edition server.
+// See: https://wiki.vg/Raknet_Protocol#Unconnected_Pong
+type BedrockInfo struct {
+	Edition  string // "MCPE" or "MCEE" for Education Edition
+	MOTD     string
+	SubMOTD  string // Second line of the MOTD, usually the level name
+	Protocol int32
+	Version  string
+	Online   int32
+	Max      int32
+	ServerID string
+	GameMode string
+	PortV4   uint16 // IPv4 port, zero when not sent
+	PortV6   uint16 // IPv6 port, zero when not sent
+}
+
+// PingBedrock pings a Bedrock Edition server with a RakNet unconnected ping over UDP.
+// As UDP is connectionless, a server which is down is only noticed by the timeout,
+// so ctx should have a deadline or the client a Timeout.
+func (c *Client) PingBedrock(ctx context.Context, host string, port uint16) (*BedrockInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	ips, err := c.resolve(ctx, host, new(Timing))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ips) == 0 {
+		return nil, errors.New("no addresses")
+	}
+
+	if c.Limiter != nil {
+		release, err := c.Limiter.Wait(ctx, ips[0])
+
+		if err != nil {
+			return nil, err
+		}
+
+		defer release()
+	}
+
+	conn, err := c.dialer().DialContext(ctx, "udp", net.JoinHostPort(ips[0].String(), strconv.Itoa(int(port))))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	defer watchContext(ctx, conn)()
+
+	ping := make([]byte, 0, 33)
+	ping = append(ping, raknetUnconnectedPing)
+	ping = appendUint64(ping, uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+	ping = append(ping, raknetMagic...)
+	ping = appendUint64(ping, rand.Uint64()) // Client GUID
+
+	deadline := phaseDeadline(ctx, c.ReadTimeout)
+	buf := make([]byte, 1500)
+
+	for {
+		if _, err = conn.Write(ping); err != nil {
+			return nil, err
+		}
+
+		resend := time.Now().Add(bedrockResendInterval)
+
+		if !deadline.IsZero() && deadline.Before(resend) {
+			resend = deadline
+		}
+
+		_ = conn.SetReadDeadline(resend)
+
+		n, err := conn.Read(buf)
+
+		if err == nil {
+			return parseBedrockPong(buf[:n])
+		}
+
+		var netErr net.Error
+
+		// Resend until the deadline, the ping or pong may have been lost
+		if !errors.As(err, &netErr) || !netErr.Timeout() || ctx.Err() != nil || !deadline.IsZero() && !time.Now().Before(deadline) {
+			return nil, err
+		}
+	}
+}
+
+// Parses an unconnected pong.
+func parseBedrockPong(b []byte) (*BedrockInfo, error) {
+	// ID, time, server GUID, magic & string length
+	const headerSize = 1 + 8 + 8 + 16 + 2
+
+	if len(b) < headerSize {
+		return nil, errors.New("bedrock pong too short")
+	}
+
+	if b[0] != raknetUnconnectedPong {
+		return nil, errors.New("unexpected RakNet packet " + strconv.Itoa(int(b[0])))
+	}
+
+	if !bytes.Equal(b[17:33], raknetMagic) {
+		return nil, errors.New("invalid RakNet magic")
+	}
+
+	length := int(binary.BigEndian.Uint16(b[33:35]))
+
+	if len(b) < headerSize+length {
+		return nil, errors.New("bedrock pong too short")
+	}
+
+	return parseBedrockStatus(string(b[headerSize : headerSize+length]))
+}
+
+// Parses the ;-separated server info of an unconnected pong.
+func parseBedrockStatus(s string) (*BedrockInfo, error) {
+	fields := strings.Split(s, ";")
+
+	if len(fields) < 6 {
+		return nil, errors.New("could not parse bedrock status: too few fields")
+	}
+
+	info := &BedrockInfo{Edition: fields[0], MOTD: fields[1], Version: fields[3]}
+
+	protocol, err := strconv.ParseInt(fields[2], 10, 32)
+
+	if err != nil {
+		return nil, errors.New("could not parse bedrock protocol: " + err.Error())
+	}
+
+	info.Protocol = int32(protocol)
+
+	if info.Online, info.Max, err = parseLegacyPlayers(fields[4], fields[5]); err != nil {
+		return nil, err
+	}
+
+	// Optional fields, missing on older servers
+	optional := []*string{&info.ServerID, &info.SubMOTD, &info.GameMode}
+
+	for i, field := range optional {
+		if 6+i < len(fields) {
+			*field = fields[6+i]
+		}
+	}
+
+	// Field 9 is the numeric game mode
+	if len(fields) > 11 {
+		v4, _ := strconv.ParseUint(fields[10], 10, 16)
+		v6, _ := strconv.ParseUint(fields[11], 10, 16)
+		info.PortV4, info.PortV6 = uint16(v4), uint16(v6)
+	}
+
+	return info, nil
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return append(b, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,101 @@
+package mcpinger
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+const bedrockStatus = "MCPE;Dedicated Server;685;1.21.0;2;10;13253860892328930865;Bedrock level;Survival;1;19132;19133;"
+
+func TestParseBedrockStatus(t *testing.T) {
+	info, err := parseBedrockStatus(bedrockStatus)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := BedrockInfo{
+		Edition:  "MCPE",
+		MOTD:     "Dedicated Server",
+		SubMOTD:  "Bedrock level",
+		Protocol: 685,
+		Version:  "1.21.0",
+		Online:   2,
+		Max:      10,
+		ServerID: "13253860892328930865",
+		GameMode: "Survival",
+		PortV4:   19132,
+		PortV6:   19133,
+	}
+
+	if *info != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *info)
+	}
+
+	// Older servers only send the first fields
+	if info, err = parseBedrockStatus("MCPE;Old Server;100;0.15.0;0;20"); err != nil || info.Max != 20 {
+		t.Errorf("Unable to parse short status: %+v %v", info, err)
+	}
+}
+
+func TestClientPingBedrock(t *testing.T) {
+	host, port := serveBedrock(t, bedrockStatus, 1)
+
+	info, err := NewClient(WithTimeout(2*time.Second)).PingBedrock(context.Background(), host, port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Version != "1.21.0" || info.Online != 2 {
+		t.Errorf("Unexpected bedrock info %+v", info)
+	}
+}
+
+// Starts a loopback Bedrock server answering unconnected pings with the given status,
+// ignoring the first drop pings like a lossy network.
+func serveBedrock(t *testing.T, status string, drop int) (string, uint16) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+
+			if err != nil {
+				return
+			}
+
+			if n != 33 || buf[0] != raknetUnconnectedPing {
+				continue
+			}
+
+			if drop > 0 {
+				drop--
+				continue
+			}
+
+			pong := []byte{raknetUnconnectedPong}
+			pong = append(pong, buf[1:9]...)              // Time of the ping
+			pong = appendUint64(pong, 0x1122334455667788) // Server GUID
+			pong = append(pong, raknetMagic...)
+			pong = appendUint16(pong, uint16(len(status)))
+			pong = append(pong, status...)
+
+			_, _ = conn.WriteTo(pong, addr)
+		}
+	}()
+
+	addr := conn.LocalAddr().(*net.UDPAddr)
+
+	return addr.IP.String(), uint16(addr.Port)
+}
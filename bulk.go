@@ -0,0 +1,72 @@
+package mcpinger
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBulkConcurrency is the amount of concurrent pings of QueryAll by default.
+const DefaultBulkConcurrency = 16
+
+// Target is a server to ping.
+type Target struct {
+	Host string
+	Port uint16
+}
+
+// BulkResult is the result of pinging a Target with QueryAll.
+type BulkResult struct {
+	Target Target
+	Result *Result // Result of the ping, nil when it failed
+	Err    error   // Error of the ping
+}
+
+// QueryAll pings all targets, with at most concurrency pings at once, sending the
+// results on the returned channel in the order they complete. The channel is closed
+// when all targets were pinged or ctx is done. The connections made are limited
+// by the client's Limiter, which is recommended when pinging many servers.
+func (c *Client) QueryAll(ctx context.Context, targets []Target, concurrency int) <-chan BulkResult {
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	results := make(chan BulkResult, concurrency)
+	work := make(chan Target)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency && i < len(targets); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for target := range work {
+				res, err := c.Query(ctx, target.Host, target.Port)
+
+				select {
+				case results <- BulkResult{Target: target, Result: res, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(results)
+
+	send:
+		for _, target := range targets {
+			select {
+			case work <- target:
+			case <-ctx.Done():
+				break send
+			}
+		}
+
+		close(work)
+		wg.Wait()
+	}()
+
+	return results
+}
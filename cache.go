@@ -0,0 +1,258 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTimeout is the timeout of pings made by a Cache for clients without a Timeout.
+const DefaultCacheTimeout = 10 * time.Second
+
+// Cache caches the results of pings, coalescing concurrent pings of the same server,
+// so services asking for the same server's status don't each open a connection.
+// Entries are kept per Client, as clients with different options may get different results.
+// Cached results are shared between callers and must not be modified.
+// It is safe for concurrent use.
+type Cache struct {
+	TTL         time.Duration // How long results are fresh, results aren't cached when zero
+	NegativeTTL time.Duration // How long failures are cached, failures aren't cached when zero
+
+	// StaleTTL is how long after expiring a result is still served,
+	// while the server is pinged again in the background.
+	// Disabled when zero.
+	StaleTTL time.Duration
+
+	// Timeout of pings of clients without a Timeout, DefaultCacheTimeout when zero.
+	// Pings are shared between callers, so they aren't bound by the deadline of a single caller.
+	Timeout time.Duration
+
+	Now func() time.Time // Current time, time.Now when nil
+
+	mu        sync.Mutex
+	entries   map[cacheKey]*cacheEntry
+	calls     map[cacheKey]*cacheCall
+	lastSweep time.Time
+}
+
+type cacheKey struct {
+	client *Client
+	host   string
+	port   uint16
+}
+
+type cacheEntry struct {
+	res *Result
+	err error
+	at  time.Time
+}
+
+// In-flight ping, shared by all callers asking for the same server.
+type cacheCall struct {
+	done    chan struct{}
+	res     *Result
+	err     error
+	waiters int // Callers waiting for the result, revalidations run without any
+	cancel  context.CancelFunc
+}
+
+// NewCache Creates a new Cache keeping results & failures for ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{TTL: ttl, NegativeTTL: ttl}
+}
+
+// WithCache makes pings go through the given cache, which may be shared by many clients.
+func WithCache(cache *Cache) McPingerOption {
+	return func(p *mcPinger) {
+		p.Cache = cache
+	}
+}
+
+// Query returns the cached result of pinging the server with client,
+// pinging it when there is none.
+func (c *Cache) Query(ctx context.Context, client *Client, host string, port uint16) (*Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	key := cacheKey{client: client, host: host, port: port}
+	now := c.clock()
+
+	c.mu.Lock()
+
+	if e, ok := c.entries[key]; ok {
+		age := now.Sub(e.at)
+
+		if e.err != nil && age < c.NegativeTTL {
+			c.mu.Unlock()
+			return nil, e.err
+		}
+
+		if e.err == nil && age < c.TTL {
+			c.mu.Unlock()
+			return e.res, nil
+		}
+
+		if e.err == nil && age < c.TTL+c.StaleTTL {
+			// Serve stale, revalidating in the background
+			if _, ok := c.calls[key]; !ok {
+				c.start(ctx, key)
+			}
+
+			c.mu.Unlock()
+			return e.res, nil
+		}
+	}
+
+	call, ok := c.calls[key]
+
+	if !ok {
+		call = c.start(ctx, key)
+	}
+
+	call.waiters++
+
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.res, call.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// Abort the ping when nobody is waiting for it anymore,
+		// including revalidations which callers joined after the result expired
+		// Later callers start a new ping instead of joining the aborted one
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate removes the cached results of the server for all clients.
+func (c *Cache) Invalidate(host string, port uint16) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if key.host == host && key.port == port {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Starts pinging the server in the background, c.mu must be held.
+// The ping is detached from the cancellation of ctx, as other callers may join it,
+// and bound by the Timeout of the client or the cache instead.
+func (c *Cache) start(ctx context.Context, key cacheKey) *cacheCall {
+	timeout := key.client.Timeout
+
+	if timeout <= 0 {
+		timeout = c.timeout()
+	}
+
+	flightCtx, cancel := context.WithTimeout(detachedContext{ctx}, timeout)
+	call := &cacheCall{done: make(chan struct{}), cancel: cancel}
+
+	if c.calls == nil {
+		c.calls = make(map[cacheKey]*cacheCall)
+	}
+
+	c.calls[key] = call
+
+	go func() {
+		defer cancel()
+
+		now := c.clock()
+		res, err := key.client.queryRetry(flightCtx, key.host, key.port)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		call.res, call.err = res, err
+		close(call.done)
+
+		// An aborted call may have been replaced already
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+
+		// Pings aborted by their callers say nothing about the server
+		if errors.Is(flightCtx.Err(), context.Canceled) {
+			return
+		}
+
+		c.store(key, &cacheEntry{res: res, err: err, at: now})
+	}()
+
+	return call
+}
+
+// Stores an entry, c.mu must be held.
+func (c *Cache) store(key cacheKey, e *cacheEntry) {
+	if e.err == nil && c.TTL <= 0 || e.err != nil && c.NegativeTTL <= 0 {
+		return
+	}
+
+	if c.entries == nil {
+		c.entries = make(map[cacheKey]*cacheEntry)
+	}
+
+	c.entries[key] = e
+
+	// Drop expired entries now and then, so the cache doesn't grow with every server ever pinged
+	maxAge := c.TTL + c.StaleTTL
+
+	if c.NegativeTTL > maxAge {
+		maxAge = c.NegativeTTL
+	}
+
+	if e.at.Sub(c.lastSweep) < maxAge {
+		return
+	}
+
+	c.lastSweep = e.at
+
+	for k, old := range c.entries {
+		if e.at.Sub(old.at) >= maxAge {
+			delete(c.entries, k)
+		}
+	}
+}
+
+func (c *Cache) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultCacheTimeout
+	}
+	return c.Timeout
+}
+
+func (c *Cache) clock() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// Context keeping the values of its parent, like the PingTrace,
+// without its deadline & cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
@@ -0,0 +1,340 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Raqbit/mc-pinger/internal/mctest"
+)
+
+// Clock which only moves when told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestCache(ttl time.Duration) (*Cache, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	cache := NewCache(ttl)
+	cache.Now = clock.Now
+	return cache, clock
+}
+
+func TestCacheCoalescing(t *testing.T) {
+	// Only a single connection is answered
+	host, port, conns := serveSequence(t, statusReply(GetTestFileContents(t, "info.json")))
+
+	// Slow down connecting, so all pings overlap
+	dialer := &net.Dialer{Control: func(network, address string, c syscall.RawConn) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}}
+
+	client := NewClient(WithTimeout(time.Second), WithDialer(dialer), WithCache(NewCache(time.Minute)))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.Ping(context.Background(), host, port); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Errorf("Expected concurrent pings to share a connection, got %d connections", n)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	reply := statusReply(GetTestFileContents(t, "info.json"))
+	host, port, conns := serveSequence(t, reply, reply)
+
+	cache, clock := newTestCache(time.Minute)
+	client := NewClient(WithTimeout(time.Second), WithCache(cache))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Query(context.Background(), host, port); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Fatalf("Expected a single connection within the TTL, got %d", n)
+	}
+
+	clock.Add(time.Minute)
+
+	if _, err := client.Query(context.Background(), host, port); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt32(conns); n != 2 {
+		t.Errorf("Expected the server to be pinged again after the TTL, got %d connections", n)
+	}
+}
+
+func TestCacheNegative(t *testing.T) {
+	host, port, conns := serveSequence(t, nil, statusReply(GetTestFileContents(t, "info.json")))
+
+	cache, clock := newTestCache(time.Minute)
+	cache.NegativeTTL = 5 * time.Second
+
+	client := NewClient(WithTimeout(time.Second), WithCache(cache))
+
+	_, err := client.Query(context.Background(), host, port)
+
+	if err == nil {
+		t.Fatal("Expected the first ping to fail")
+	}
+
+	if _, cached := client.Query(context.Background(), host, port); cached != err {
+		t.Errorf("Expected the failure to be cached, got %v", cached)
+	}
+
+	clock.Add(5 * time.Second)
+
+	if _, err = client.Query(context.Background(), host, port); err != nil {
+		t.Errorf("Expected the server to be pinged again after the negative TTL, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(conns); n != 2 {
+		t.Errorf("Expected 2 connections, got %d", n)
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	host, port, conns := serveSequence(t,
+		statusReply([]byte(`{"version":{"name":"1.20.4","protocol":765}}`)),
+		statusReply([]byte(`{"version":{"name":"1.21","protocol":767}}`)),
+	)
+
+	cache, clock := newTestCache(time.Minute)
+	cache.StaleTTL = time.Minute
+
+	client := NewClient(WithTimeout(time.Second), WithCache(cache))
+
+	if _, err := client.Ping(context.Background(), host, port); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Add(90 * time.Second)
+
+	info, err := client.Ping(context.Background(), host, port)
+
+	if err != nil || info.Version.Name != "1.20.4" {
+		t.Fatalf("Expected the stale result, got %v %v", info, err)
+	}
+
+	// Wait for the revalidation
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		cache.mu.Lock()
+		revalidating := len(cache.calls) > 0
+		cache.mu.Unlock()
+
+		if !revalidating {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Revalidation did not finish")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if info, err = client.Ping(context.Background(), host, port); err != nil || info.Version.Name != "1.21" {
+		t.Errorf("Expected the revalidated result, got %v %v", info, err)
+	}
+
+	if n := atomic.LoadInt32(conns); n != 2 {
+		t.Errorf("Expected 2 connections, got %d", n)
+	}
+}
+
+func TestCacheCallerCancelled(t *testing.T) {
+	host, port := serveSilent(t)
+
+	cache := NewCache(time.Minute)
+	client := NewClient(WithCache(cache))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Query(ctx, host, port); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+
+	// The abandoned ping is aborted and not cached
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		cache.mu.Lock()
+		calls, entries := len(cache.calls), len(cache.entries)
+		cache.mu.Unlock()
+
+		if calls == 0 {
+			if entries != 0 {
+				t.Error("Expected the aborted ping not to be cached")
+			}
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the abandoned ping to be aborted")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCacheTimeout(t *testing.T) {
+	host, port := serveSilent(t)
+
+	cache := NewCache(time.Minute)
+	cache.Timeout = 100 * time.Millisecond
+
+	// Neither the client nor the caller has a deadline
+	client := NewClient(WithCache(cache))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Query(context.Background(), host, port)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("Expected the deadline to be exceeded, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the ping to time out")
+	}
+}
+
+func TestCacheStaleJoinedCancelled(t *testing.T) {
+	host, port := serveSilent(t)
+
+	cache, clock := newTestCache(time.Minute)
+	cache.StaleTTL = time.Minute
+	client := NewClient(WithCache(cache))
+
+	cache.mu.Lock()
+	cache.store(cacheKey{client: client, host: host, port: port}, &cacheEntry{res: &Result{Info: new(ServerInfo)}, at: clock.Now()})
+	cache.mu.Unlock()
+
+	// Serving stale starts a revalidation, which hangs
+	clock.Add(90 * time.Second)
+
+	if _, err := client.Query(context.Background(), host, port); err != nil {
+		t.Fatalf("Expected the stale result, got %v", err)
+	}
+
+	// Once the stale result expired too, callers join the revalidation
+	clock.Add(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Query(ctx, host, port); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+
+	// The revalidation is aborted after its last caller left
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		cache.mu.Lock()
+		calls := len(cache.calls)
+		cache.mu.Unlock()
+
+		if calls == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the abandoned revalidation to be aborted")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCacheCallerCancelledRejoin(t *testing.T) {
+	reply := statusReply(GetTestFileContents(t, "info.json"))
+	release := make(chan struct{})
+	var pings int32
+
+	// The first connection hangs, the others are answered
+	host, port, _ := mctest.Serve(t, func(conn net.Conn, hs mctest.Handshake) {
+		if atomic.AddInt32(&pings, 1) == 1 {
+			<-release
+			return
+		}
+
+		_, _ = conn.Write(reply)
+	})
+
+	t.Cleanup(func() { close(release) })
+
+	client := NewClient(WithCache(NewCache(time.Minute)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Query(ctx, host, port); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+
+	// Right after the last caller left, a new caller doesn't join the aborted ping
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := client.Query(ctx, host, port); err != nil {
+		t.Errorf("Expected a new ping, got %v", err)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	reply := statusReply(GetTestFileContents(t, "info.json"))
+	host, port, conns := serveSequence(t, reply, reply)
+
+	cache := NewCache(time.Minute)
+	client := NewClient(WithTimeout(time.Second), WithCache(cache))
+
+	_, _ = client.Query(context.Background(), host, port)
+	cache.Invalidate(host, port)
+	_, _ = client.Query(context.Background(), host, port)
+
+	if n := atomic.LoadInt32(conns); n != 2 {
+		t.Errorf("Expected the server to be pinged again after invalidating, got %d connections", n)
+	}
+}
@@ -2,6 +2,9 @@ package mcpinger
 
 import (
 	"encoding/json"
+	"html"
+	"strings"
+	"unicode"
 )
 
 // RegularChatComponent is a Minecraft chat component
@@ -16,6 +19,9 @@ type RegularChatComponent struct {
 	Obfuscated    bool            `json:"obfuscated"`    // Component randomly switches between characters of the same width
 	Color         string          `json:"color"`         // Contains the color for the component
 	Extra         []ChatComponent `json:"extra"`         // siblings
+	Translate     string          `json:"translate"`     // Translation key, see Translator
+	With          []ChatComponent `json:"with"`          // Arguments of the translation
+	Fallback      string          `json:"fallback"`      // Text when the translation key is unknown
 }
 
 // ChatComponent wraps a RegularChatComponent for parsing both regular & string-only MOTD's
@@ -27,19 +33,52 @@ type ChatComponent struct {
 func (c *ChatComponent) UnmarshalJSON(data []byte) error {
 	var regular RegularChatComponent
 
+	// Empty data, like an empty disconnect reason, is empty text
+	if len(data) == 0 {
+		c.RegularChatComponent = regular
+		return nil
+	}
+
 	// data can be
 	// {"text":"Foo"}
 	// "Bar"
+	// ["Foo",{"text":"Bar"}]
+	// 42, true or null
 
-	// The data starts with quotes which means it's a string, not an object
-	if data[0] == '"' {
+	switch data[0] {
+	case '"':
+		// The data starts with quotes which means it's a string, not an object
 		var text string
 		if err := json.Unmarshal(data, &text); err != nil {
 			return err
 		}
 
 		regular.Text = text
-	} else {
+	case '[':
+		// The first component of an array is the parent of the others
+		var components []ChatComponent
+		if err := json.Unmarshal(data, &components); err != nil {
+			return err
+		}
+
+		if len(components) > 0 {
+			regular = components[0].RegularChatComponent
+			regular.Extra = append(regular.Extra, components[1:]...)
+		}
+	case 'n':
+		// null is an empty component
+		if err := json.Unmarshal(data, new(interface{})); err != nil {
+			return err
+		}
+	case 't', 'f', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		// Numbers & booleans, which may be translation arguments
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+
+		regular.Text = string(data)
+	default:
 		if err := json.Unmarshal(data, &regular); err != nil {
 			return err
 		}
@@ -49,3 +88,225 @@ func (c *ChatComponent) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// TextSpan is a run of text with a single style,
+// as rendered from a ChatComponent.
+type TextSpan struct {
+	Text          string
+	Color         string // Color name or #rrggbb, empty for the default color
+	Bold          bool
+	Italic        bool
+	Underlined    bool
+	Strikethrough bool
+	Obfuscated    bool
+}
+
+// Legacy § formatting code colors
+var legacyColors = map[rune]string{
+	'0': "black",
+	'1': "dark_blue",
+	'2': "dark_green",
+	'3': "dark_aqua",
+	'4': "dark_red",
+	'5': "dark_purple",
+	'6': "gold",
+	'7': "gray",
+	'8': "dark_gray",
+	'9': "blue",
+	'a': "green",
+	'b': "aqua",
+	'c': "red",
+	'd': "light_purple",
+	'e': "yellow",
+	'f': "white",
+}
+
+// ColorHex maps color names to their hex value.
+var ColorHex = map[string]string{
+	"black":        "#000000",
+	"dark_blue":    "#0000aa",
+	"dark_green":   "#00aa00",
+	"dark_aqua":    "#00aaaa",
+	"dark_red":     "#aa0000",
+	"dark_purple":  "#aa00aa",
+	"gold":         "#ffaa00",
+	"gray":         "#aaaaaa",
+	"dark_gray":    "#555555",
+	"blue":         "#5555ff",
+	"green":        "#55ff55",
+	"aqua":         "#55ffff",
+	"red":          "#ff5555",
+	"light_purple": "#ff55ff",
+	"yellow":       "#ffff55",
+	"white":        "#ffffff",
+}
+
+// Spans returns the text of the component & its siblings as styled spans,
+// applying inherited styles and legacy § formatting codes.
+func (c ChatComponent) Spans() []TextSpan {
+	return c.appendSpans(nil, TextSpan{})
+}
+
+func (c ChatComponent) appendSpans(spans []TextSpan, parent TextSpan) []TextSpan {
+	style := TextSpan{
+		Color:         parent.Color,
+		Bold:          parent.Bold || c.Bold,
+		Italic:        parent.Italic || c.Italic,
+		Underlined:    parent.Underlined || c.Underlined,
+		Strikethrough: parent.Strikethrough || c.Strikethrough,
+		Obfuscated:    parent.Obfuscated || c.Obfuscated,
+	}
+
+	if c.Color != "" {
+		style.Color = c.Color
+	}
+
+	spans = appendLegacySpans(spans, c.Text, style)
+
+	// Untranslated components show their fallback or key, like an unknown key
+	if c.Translate != "" {
+		text := c.Fallback
+
+		if text == "" {
+			text = c.Translate
+		}
+
+		spans = appendLegacySpans(spans, text, style)
+	}
+
+	for _, extra := range c.Extra {
+		spans = extra.appendSpans(spans, style)
+	}
+
+	return spans
+}
+
+// Appends the spans of text containing legacy § formatting codes.
+func appendLegacySpans(spans []TextSpan, text string, base TextSpan) []TextSpan {
+	cur := base
+	var b strings.Builder
+	code := false
+
+	flush := func() {
+		if b.Len() > 0 {
+			cur.Text = b.String()
+			spans = append(spans, cur)
+			b.Reset()
+		}
+	}
+
+	for _, r := range text {
+		if !code {
+			if r == '§' {
+				code = true
+			} else {
+				b.WriteRune(r)
+			}
+			continue
+		}
+
+		code = false
+		flush()
+
+		if color, ok := legacyColors[unicode.ToLower(r)]; ok {
+			// Colors reset the formatting
+			cur = TextSpan{Color: color}
+			continue
+		}
+
+		switch unicode.ToLower(r) {
+		case 'k':
+			cur.Obfuscated = true
+		case 'l':
+			cur.Bold = true
+		case 'm':
+			cur.Strikethrough = true
+		case 'n':
+			cur.Underlined = true
+		case 'o':
+			cur.Italic = true
+		case 'r':
+			cur = base
+		}
+	}
+
+	flush()
+
+	return spans
+}
+
+// PlainText returns the text of the component & its siblings,
+// without any formatting or legacy § formatting codes.
+func (c ChatComponent) PlainText() string {
+	var b strings.Builder
+
+	for _, span := range c.Spans() {
+		b.WriteString(span.Text)
+	}
+
+	return b.String()
+}
+
+// HTML returns the component & its siblings as HTML, using a styled span
+// element for each run of formatted text. Newlines become line breaks.
+func (c ChatComponent) HTML() string {
+	var b strings.Builder
+
+	for _, span := range c.Spans() {
+		var style []string
+
+		if hex, ok := ColorHex[span.Color]; ok {
+			style = append(style, "color:"+hex)
+		} else if isHexColor(span.Color) {
+			style = append(style, "color:"+span.Color)
+		}
+
+		if span.Bold {
+			style = append(style, "font-weight:bold")
+		}
+
+		if span.Italic {
+			style = append(style, "font-style:italic")
+		}
+
+		var decorations []string
+
+		if span.Underlined {
+			decorations = append(decorations, "underline")
+		}
+
+		if span.Strikethrough {
+			decorations = append(decorations, "line-through")
+		}
+
+		if len(decorations) > 0 {
+			style = append(style, "text-decoration:"+strings.Join(decorations, " "))
+		}
+
+		text := strings.Replace(html.EscapeString(span.Text), "\n", "<br>", -1)
+
+		if len(style) == 0 {
+			b.WriteString(text)
+			continue
+		}
+
+		b.WriteString(`<span style="` + strings.Join(style, ";") + `">` + text + "</span>")
+	}
+
+	return b.String()
+}
+
+// Reports whether color is a #rrggbb color.
+func isHexColor(color string) bool {
+	if len(color) != 7 || color[0] != '#' {
+		return false
+	}
+
+	for _, r := range color[1:] {
+		if !unicode.Is(unicode.ASCII_Hex_Digit, r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,121 @@
+package mcpinger
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChatComponentPlainText(t *testing.T) {
+	tests := []struct {
+		Json     string
+		Expected string
+	}{
+		{`"Hello world"`, "Hello world"},
+		{`"§aHello §l§cworld"`, "Hello world"},
+		{`{"text":"Hello ","extra":[{"text":"world","bold":true},{"text":"!","extra":["?"]}]}`, "Hello world!?"},
+		{`{"text":"","extra":[{"text":"§6Gold"}]}`, "Gold"},
+		{`["",{"text":"hi"}]`, "hi"},
+		{`[{"text":"Hello "},"world",{"text":"!"}]`, "Hello world!"},
+		{`[]`, ""},
+		{`null`, ""},
+		{`{"text":"","extra":[42,true]}`, "42true"},
+	}
+
+	for _, test := range tests {
+		var c ChatComponent
+
+		if err := json.Unmarshal([]byte(test.Json), &c); err != nil {
+			t.Fatalf("Unable to parse %s: %v", test.Json, err)
+		}
+
+		if actual := c.PlainText(); actual != test.Expected {
+			t.Errorf("Unable to render %s as plain text, expected %q, got %q", test.Json, test.Expected, actual)
+		}
+	}
+}
+
+func TestChatComponentSpans(t *testing.T) {
+	var c ChatComponent
+
+	data := `{"text":"§lBold §cred","extra":[{"text":"§rgreen","color":"green","italic":true}]}`
+
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TextSpan{
+		{Text: "Bold ", Bold: true},
+		{Text: "red", Color: "red"},
+		{Text: "green", Color: "green", Italic: true},
+	}
+
+	spans := c.Spans()
+
+	if len(spans) != len(expected) {
+		t.Fatalf("Expected %d spans, got %+v", len(expected), spans)
+	}
+
+	for i := range expected {
+		if spans[i] != expected[i] {
+			t.Errorf("Span %d: expected %+v, got %+v", i, expected[i], spans[i])
+		}
+	}
+}
+
+func TestChatComponentArrayStyle(t *testing.T) {
+	var c ChatComponent
+
+	if err := json.Unmarshal([]byte(`[{"text":"Red ","color":"red"},"inherited",{"text":" blue","color":"blue"}]`), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"red", "red", "blue"}
+	spans := c.Spans()
+
+	if len(spans) != len(expected) {
+		t.Fatalf("Expected %d spans, got %+v", len(expected), spans)
+	}
+
+	// Elements after the first inherit its style
+	for i, span := range spans {
+		if span.Color != expected[i] {
+			t.Errorf("Expected span %q to be %s, got %q", span.Text, expected[i], span.Color)
+		}
+	}
+}
+
+func TestChatComponentNullDescription(t *testing.T) {
+	var info ServerInfo
+
+	if err := json.Unmarshal([]byte(`{"description":null}`), &info); err != nil {
+		t.Fatal(err)
+	}
+
+	if text := info.Description.PlainText(); text != "" {
+		t.Errorf("Expected an empty description, got %q", text)
+	}
+}
+
+func TestChatComponentHTML(t *testing.T) {
+	tests := []struct {
+		Json     string
+		Expected string
+	}{
+		{`"<script>"`, "&lt;script&gt;"},
+		{`"§aLine 1\n§lLine 2"`, `<span style="color:#55ff55">Line 1<br></span><span style="color:#55ff55;font-weight:bold">Line 2</span>`},
+		{`{"text":"Custom","color":"#123abc","underlined":true,"strikethrough":true}`, `<span style="color:#123abc;text-decoration:underline line-through">Custom</span>`},
+		{`{"text":"Bad","color":"#zzzzzz"}`, "Bad"},
+	}
+
+	for _, test := range tests {
+		var c ChatComponent
+
+		if err := json.Unmarshal([]byte(test.Json), &c); err != nil {
+			t.Fatalf("Unable to parse %s: %v", test.Json, err)
+		}
+
+		if actual := c.HTML(); actual != test.Expected {
+			t.Errorf("Unable to render %s as HTML, expected %q, got %q", test.Json, test.Expected, actual)
+		}
+	}
+}
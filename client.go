@@ -0,0 +1,533 @@
+package mcpinger
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/pires/go-proxyproto"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"github.com/Raqbit/mc-pinger/packet"
+)
+
+// Client pings Minecraft servers using shared options.
+// A Client is safe for repeated and concurrent use, as long as
+// its fields are not modified while pinging.
+type Client struct {
+	Dialer   *net.Dialer   // Dialer used to connect, a zero net.Dialer when nil
+	Resolver *net.Resolver // Resolver used to look up hosts, overrides the Dialer's
+	Timeout  time.Duration // Timeout of a ping including its retries, no timeout when zero
+
+	DialTimeout  time.Duration // Timeout of resolving the host and connecting
+	WriteTimeout time.Duration // Timeout of writing the PROXY header and handshake
+	ReadTimeout  time.Duration // Timeout of reading the status response
+	PongTimeout  time.Duration // Timeout of reading the pong
+
+	Latency       bool // Measure the latency with a ping/pong exchange
+	SRV           bool // Look up the _minecraft._tcp SRV record of the host
+	HappyEyeballs bool // Race connections to IPv6 and IPv4 addresses (RFC 8305)
+
+	Retry *RetryPolicy // Retry policy of Query & Ping, no retries when nil
+	Cache *Cache       // Cache of Query & Ping results, no caching when nil
+
+	Limiter *Limiter // Limits the connections made, unlimited when nil
+
+	ProtoVersion int32 // Protocol version sent in the handshake
+	FML          int   // FML marker appended to the handshake address, see WithFML
+
+	Username string // Player name sent when probing the login state
+	UUID     string // Player UUID sent when probing the login state
+
+	UseProxy         bool             // Write a PROXY protocol header after connecting
+	ProxyVersion     byte             // PROXY protocol version, 1 (text) or 2 (binary)
+	ProxySource      *net.TCPAddr     // Source address of the PROXY header, the local address when nil
+	ProxyDestination *net.TCPAddr     // Destination address of the PROXY header, the server address when nil
+	ProxyTLVs        []proxyproto.TLV // TLVs of the PROXY header, version 2 only
+
+	proxyErr error // Error of a ProxyOption, returned when connecting
+}
+
+// Result is the server info of a ping, with details about the connection.
+type Result struct {
+	Info   *ServerInfo // Server info
+	Addr   net.Addr    // Address of the server which answered
+	Family string      // Address family which answered, "tcp4" or "tcp6"
+	Timing Timing      // Time spent in each phase of the ping
+	Time   time.Time   // Time the ping started
+
+	Attempts int // Amount of attempts needed, more than one when retried
+}
+
+// AddrResult is the result of pinging a single address of a host.
+type AddrResult struct {
+	IP     net.IP
+	Result *Result // Result of the ping, nil when it failed
+	Err    error   // Error of the ping
+}
+
+// Addresses to connect to instead of resolving the host.
+type resolved struct {
+	ips  []net.IP
+	port uint16
+}
+
+// Timing is the time spent in each phase of a ping.
+type Timing struct {
+	DNS         time.Duration // Resolving the host, zero for IP addresses
+	Connect     time.Duration // Establishing the TCP connection
+	ProxyHeader time.Duration // Writing the PROXY header, zero when disabled
+	FirstByte   time.Duration // From sending the request until the first response byte
+	Transfer    time.Duration // Reading the rest of the response
+	RTT         time.Duration // Ping/pong round trip, zero unless Latency is enabled
+}
+
+// NewClient Creates a new Client with the given options.
+// WithContext has no effect, as the context is passed per ping.
+func NewClient(options ...McPingerOption) *Client {
+	return &newPinger("", 0, options).Client
+}
+
+// Ping connects to the Minecraft server,
+// retrieves server status and returns the server info.
+func (c *Client) Ping(ctx context.Context, host string, port uint16) (*ServerInfo, error) {
+	res, err := c.Query(ctx, host, port)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Info, nil
+}
+
+// Query pings the Minecraft server like Ping, returning
+// the server info along with details about the connection.
+// Failed attempts are retried according to the Retry policy.
+// When the client has a Cache, results may be served from it.
+func (c *Client) Query(ctx context.Context, host string, port uint16) (*Result, error) {
+	if c.Cache != nil {
+		return c.Cache.Query(ctx, c, host, port)
+	}
+
+	return c.queryRetry(ctx, host, port)
+}
+
+// Pings the server, retrying failed attempts.
+func (c *Client) queryRetry(ctx context.Context, host string, port uint16) (*Result, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// The timeout is the budget of all attempts, retries stop when it runs out
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	return c.Retry.do(ctx, func() (*Result, error) {
+		return c.query(ctx, host, port, nil)
+	})
+}
+
+// QueryAddrs resolves the host and pings every IPv4 and IPv6 address of it
+// concurrently, returning the result of each address. An error is only
+// returned when the host could not be resolved.
+func (c *Client) QueryAddrs(ctx context.Context, host string, port uint16) ([]AddrResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	var timing Timing
+	target, targetPort := host, port
+
+	if c.SRV {
+		target, targetPort = c.lookupSRV(ctx, host, port, &timing)
+	}
+
+	ips, err := c.resolve(ctx, target, &timing)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve Minecraft server: %w", err)
+	}
+
+	results := make([]AddrResult, len(ips))
+	var wg sync.WaitGroup
+
+	for i, ip := range ips {
+		wg.Add(1)
+		go func(i int, ip net.IP) {
+			defer wg.Done()
+
+			res, err := c.query(ctx, host, port, &resolved{ips: []net.IP{ip}, port: targetPort})
+
+			if res != nil {
+				res.Timing.DNS = timing.DNS
+			}
+
+			results[i] = AddrResult{IP: ip, Result: res, Err: err}
+		}(i, ip)
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+// Pings the server, connecting to addrs instead of resolving the host when set.
+func (c *Client) query(ctx context.Context, host string, port uint16, addrs *resolved) (*Result, error) {
+	res, err := c.queryVersion(ctx, host, port, addrs, c.ProtoVersion)
+
+	if err != nil || c.FML != FMLAuto || c.ProtoVersion != UnknownProtoVersion {
+		return res, err
+	}
+
+	// The FML marker depends on the version of the server, which is only known now
+	protoVer := res.Info.Version.Protocol
+
+	if protoVer <= 0 || FMLMarker(protoVer) == "" {
+		return res, nil
+	}
+
+	if marked, err := c.queryVersion(ctx, host, port, addrs, protoVer); err == nil {
+		return marked, nil
+	}
+
+	return res, nil
+}
+
+// Pings the server with protoVer in the handshake.
+func (c *Client) queryVersion(ctx context.Context, host string, port uint16, addrs *resolved, protoVer int32) (*Result, error) {
+	trace := ContextPingTrace(ctx)
+	res := &Result{Time: time.Now()}
+
+	conn, err := c.connect(ctx, host, port, addrs, &res.Timing)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	defer watchContext(ctx, conn)()
+
+	res.Addr = conn.RemoteAddr()
+	res.Family = family(res.Addr)
+
+	rd := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	err = sendHandshakePacket(w, c.handshakeAddress(host, protoVer), port, protoVer, StatusState)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = sendRequestPacket(w)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetWriteDeadline(phaseDeadline(ctx, c.WriteTimeout))
+	err = w.Flush()
+	trace.handshakeSent(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sent := time.Now()
+	_ = conn.SetReadDeadline(phaseDeadline(ctx, c.ReadTimeout))
+
+	// Wait for the response to arrive
+	if _, err = rd.Peek(1); err != nil {
+		return nil, err
+	}
+
+	received := time.Now()
+	res.Timing.FirstByte = received.Sub(sent)
+
+	resp, err := readResponsePacket(rd, trace)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.Timing.Transfer = time.Since(received)
+
+	res.Info, err = parseServerInfo([]byte(resp.Json))
+	trace.responseParsed(res.Info, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Latency {
+		_ = conn.SetReadDeadline(phaseDeadline(ctx, c.PongTimeout))
+
+		res.Timing.RTT, err = pingPong(rd, w)
+		trace.pongReceived(res.Timing.RTT, err)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not measure latency: %w", err)
+		}
+	}
+
+	return res, nil
+}
+
+// Derives a context from ctx which expires after the Timeout.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.Timeout > 0 {
+		return context.WithTimeout(ctx, c.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Returns the earliest of the context deadline and the phase timeout from now,
+// or the zero time when there is neither.
+func phaseDeadline(ctx context.Context, timeout time.Duration) time.Time {
+	deadline, _ := ctx.Deadline()
+
+	if timeout > 0 {
+		if phase := time.Now().Add(timeout); deadline.IsZero() || phase.Before(deadline) {
+			return phase
+		}
+	}
+
+	return deadline
+}
+
+// Connects to the Minecraft server, writing the PROXY header when enabled.
+// The host is resolved unless addrs is set. The time spent is recorded in timing.
+func (c *Client) connect(ctx context.Context, host string, port uint16, addrs *resolved, timing *Timing) (net.Conn, error) {
+	trace := ContextPingTrace(ctx)
+	dialCtx := ctx
+
+	if c.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, c.DialTimeout)
+		defer cancel()
+	}
+
+	if addrs == nil {
+		if c.SRV {
+			host, port = c.lookupSRV(dialCtx, host, port, timing)
+		}
+
+		ips, err := c.resolve(dialCtx, host, timing)
+
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve Minecraft server: %w", err)
+		}
+
+		addrs = &resolved{ips: ips, port: port}
+	}
+
+	start := time.Now()
+	conn, err := c.dial(dialCtx, addrs.ips, addrs.port)
+	timing.Connect = time.Since(start)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to Minecraft server: %w", err)
+	}
+
+	// When a remote process is bound, but paused, the connect succeeds;
+	// however, the response packet just never comes back.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if c.UseProxy {
+		start = time.Now()
+		_ = conn.SetWriteDeadline(phaseDeadline(ctx, c.WriteTimeout))
+		err = c.writeProxyHeader(conn)
+		timing.ProxyHeader = time.Since(start)
+		trace.proxyHeaderWritten(err)
+
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not write PROXY header: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// Looks up the SRV record of the host, returning the target and port to
+// connect to. The host and port are returned as-is when there is no record.
+func (c *Client) lookupSRV(ctx context.Context, host string, port uint16, timing *Timing) (string, uint16) {
+	if net.ParseIP(host) != nil {
+		return host, port
+	}
+
+	start := time.Now()
+	_, addrs, err := c.resolver().LookupSRV(ctx, "minecraft", "tcp", host)
+	timing.DNS += time.Since(start)
+
+	if err == nil && len(addrs) > 0 {
+		host, port = strings.TrimSuffix(addrs[0].Target, "."), addrs[0].Port
+	}
+
+	ContextPingTrace(ctx).srvResolved(host, port, err)
+
+	return host, port
+}
+
+// Resolves the host to its IP addresses, recording the time spent in timing.
+func (c *Client) resolve(ctx context.Context, host string, timing *Timing) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	trace := ContextPingTrace(ctx)
+	trace.dnsStart(host)
+
+	start := time.Now()
+	addrs, err := c.resolver().LookupIPAddr(ctx, host)
+	timing.DNS += time.Since(start)
+
+	var ips []net.IP
+
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+
+	trace.dnsDone(ips, err)
+
+	return ips, err
+}
+
+// Returns the Resolver to look up hosts with.
+func (c *Client) resolver() *net.Resolver {
+	if resolver := c.dialer().Resolver; resolver != nil {
+		return resolver
+	}
+
+	return net.DefaultResolver
+}
+
+// Returns the Dialer to connect with, using the Resolver when set.
+func (c *Client) dialer() *net.Dialer {
+	d := new(net.Dialer)
+
+	if c.Dialer != nil {
+		*d = *c.Dialer
+	}
+
+	if c.Resolver != nil {
+		d.Resolver = c.Resolver
+	}
+
+	return d
+}
+
+// Interrupts reads & writes on conn when ctx is done.
+// The returned function stops watching the context.
+func watchContext(ctx context.Context, conn net.Conn) func() {
+	stop := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	return func() { close(stop) }
+}
+
+func sendHandshakePacket(w *bufio.Writer, host string, port uint16, protoVer int32, nextState enc.VarInt) error {
+	handshakePkt := &packet.HandshakePacket{
+		ProtoVer:   enc.VarInt(protoVer),
+		ServerAddr: enc.String(host),
+		ServerPort: enc.UnsignedShort(port),
+		NextState:  nextState,
+	}
+
+	err := packet.WritePacket(handshakePkt, w)
+
+	if err != nil {
+		return errors.New("could not pack: " + err.Error())
+	}
+
+	return nil
+}
+
+func sendRequestPacket(w *bufio.Writer) error {
+	requestPkt := &packet.RequestPacket{}
+
+	err := packet.WritePacket(requestPkt, w)
+
+	if err != nil {
+		return errors.New("could not pack: " + err.Error())
+	}
+
+	return nil
+}
+
+// Sends a ping with the current time as payload and
+// returns the time until the matching pong arrived.
+func pingPong(rd *bufio.Reader, w *bufio.Writer) (time.Duration, error) {
+	start := time.Now()
+	pingPkt := &packet.PingPacket{Payload: enc.Long(start.UnixNano())}
+
+	if err := packet.WritePacket(pingPkt, w); err != nil {
+		return 0, errors.New("could not pack: " + err.Error())
+	}
+
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+
+	pp := &packet.PongPacket{}
+
+	_, packetID, err := packet.ReadPacketHeader(rd)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if packetID != pp.ID() {
+		return 0, InvalidPacketError{expected: pp.ID(), actual: packetID}
+	}
+
+	if err = pp.Unmarshal(rd); err != nil {
+		return 0, err
+	}
+
+	if pp.Payload != pingPkt.Payload {
+		return 0, errors.New("pong payload does not match ping")
+	}
+
+	return time.Since(start), nil
+}
+
+func readResponsePacket(rd *bufio.Reader, trace *PingTrace) (*packet.ResponsePacket, error) {
+
+	rp := &packet.ResponsePacket{}
+
+	length, packetID, err := packet.ReadPacketHeader(rd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	trace.responseHeaderRead(int32(length), int32(packetID))
+
+	if packetID != rp.ID() {
+		return nil, InvalidPacketError{expected: rp.ID(), actual: packetID}
+	}
+
+	err = rp.Unmarshal(rd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rp, nil
+}
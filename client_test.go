@@ -0,0 +1,160 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Raqbit/mc-pinger/internal/mctest"
+)
+
+func TestPingerRepeated(t *testing.T) {
+	host, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	pinger := New(host, port, WithTimeout(time.Second))
+
+	// The second ping used to reuse the cancelled timeout context
+	for i := 0; i < 2; i++ {
+		info, err := pinger.Ping()
+
+		if err != nil {
+			t.Fatalf("Ping %d: %v", i, err)
+		}
+
+		if info.Version.Name != "1.13.2" {
+			t.Errorf("Ping %d: Did not parse version name correctly", i)
+		}
+	}
+}
+
+func TestClientConcurrent(t *testing.T) {
+	host, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	client := NewClient(WithTimeout(time.Second))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.Ping(context.Background(), host, port); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestClientCancelledWhileReading(t *testing.T) {
+	host, port := serveSilent(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	if _, err := NewClient().Ping(ctx, host, port); err == nil {
+		t.Fatal("Expected ping to fail after cancelling")
+	}
+}
+
+func TestClientQuery(t *testing.T) {
+	host, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	client := NewClient(WithReadTimeout(time.Second), WithLatency(time.Second))
+
+	res, err := client.Query(context.Background(), host, port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Info.Version.Name != "1.13.2" {
+		t.Error("Did not parse version name correctly")
+	}
+
+	if res.Addr.String() != net.JoinHostPort(host, strconv.Itoa(int(port))) {
+		t.Errorf("Unexpected address: %s", res.Addr)
+	}
+
+	if res.Timing.DNS != 0 {
+		t.Errorf("Expected no DNS time for an IP address, got %s", res.Timing.DNS)
+	}
+
+	if res.Timing.Connect <= 0 || res.Timing.FirstByte <= 0 || res.Timing.RTT <= 0 {
+		t.Errorf("Expected connect, first byte and RTT timings, got %+v", res.Timing)
+	}
+}
+
+func TestClientReadTimeout(t *testing.T) {
+	host, port := serveSilent(t)
+
+	client := NewClient(WithReadTimeout(50 * time.Millisecond))
+
+	_, err := client.Ping(context.Background(), host, port)
+
+	var netErr net.Error
+
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+}
+
+// Starts a loopback server answering every status request with the given JSON,
+// echoing any packet sent after the request like a ping.
+func serveStatus(t *testing.T, json []byte) (string, uint16) {
+	host, port, _ := mctest.Serve(t, func(conn net.Conn, hs mctest.Handshake) {
+		_, _ = conn.Write(statusReply(json))
+		mctest.EchoPings(conn)
+	})
+
+	return host, port
+}
+
+// Starts a loopback server accepting connections without ever answering,
+// like a paused server process.
+func serveSilent(t *testing.T) (string, uint16) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conns []net.Conn
+	var mu sync.Mutex
+
+	t.Cleanup(func() {
+		l.Close()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+
+			if err != nil {
+				return
+			}
+
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), uint16(addr.Port)
+}
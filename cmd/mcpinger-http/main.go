@@ -0,0 +1,50 @@
+// Command mcpinger-http serves the status of allowed Minecraft servers as JSON over HTTP.
+//
+// Usage:
+//
+//	mcpinger-http -addr :8080 -allow mc.example.com,play.example.com:25566
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+	"github.com/Raqbit/mc-pinger/httpapi"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	allow := flag.String("allow", "", "comma separated servers which may be pinged, \"*\" allows all")
+	ttl := flag.Duration("ttl", httpapi.DefaultTTL, "how long results are cached")
+	timeout := flag.Duration("timeout", 5*time.Second, "timeout of a ping")
+	srv := flag.Bool("srv", true, "look up SRV records")
+	flag.Parse()
+
+	if *allow == "" {
+		log.Fatal("no servers allowed, pass -allow")
+	}
+
+	options := []mcpinger.McPingerOption{
+		mcpinger.WithTimeout(*timeout),
+		mcpinger.WithLatency(*timeout),
+	}
+
+	if *srv {
+		options = append(options, mcpinger.WithSRV())
+	}
+
+	handler := httpapi.NewHandler(mcpinger.NewClient(options...), httpapi.Allowlist(strings.Split(*allow, ",")), *ttl)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("Listening on %s", *addr)
+	log.Fatal(server.ListenAndServe())
+}
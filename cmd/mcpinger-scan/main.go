@@ -0,0 +1,105 @@
+// Command mcpinger-scan scans IP ranges for Minecraft servers, writing the open ports found as JSON lines.
+// Only scan networks you are allowed to.
+//
+// Usage:
+//
+//	mcpinger-scan -ports 25565,25566 -exclude 10.0.0.1 -rate 100ms -bedrock 10.0.0.0/24
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+	"github.com/Raqbit/mc-pinger/scan"
+)
+
+func main() {
+	ports := flag.String("ports", strconv.Itoa(scan.DefaultPort), "comma separated Java Edition ports to scan")
+	exclude := flag.String("exclude", "", "comma separated CIDR ranges or IP addresses to skip")
+	rate := flag.Duration("rate", 10*time.Millisecond, "minimum interval between connections, 0 for unlimited")
+	subnetRate := flag.Duration("subnet-rate", 0, "minimum interval between connections to a subnet, 0 for unlimited")
+	concurrency := flag.Int("concurrency", scan.DefaultConcurrency, "concurrent probes")
+	connectTimeout := flag.Duration("connect-timeout", scan.DefaultConnectTimeout, "timeout of the connect pass")
+	timeout := flag.Duration("timeout", 5*time.Second, "timeout of a ping")
+	legacy := flag.Bool("legacy", false, "probe open ports with the legacy ping when the status ping fails")
+	bedrock := flag.Bool("bedrock", false, "probe for Bedrock Edition servers")
+	bedrockPorts := flag.String("bedrock-ports", strconv.Itoa(mcpinger.DefaultBedrockPort), "comma separated Bedrock Edition ports to probe")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatal("no ranges to scan, pass CIDR ranges or IP addresses as arguments")
+	}
+
+	javaPorts, err := parsePorts(*ports)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	raknetPorts, err := parsePorts(*bedrockPorts)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	limiter := &mcpinger.Limiter{
+		Global:    mcpinger.Rate{Every: *rate},
+		PerSubnet: mcpinger.Rate{Every: *subnetRate},
+	}
+
+	scanner := &scan.Scanner{
+		Ranges:         flag.Args(),
+		Ports:          javaPorts,
+		Client:         mcpinger.NewClient(mcpinger.WithTimeout(*timeout), mcpinger.WithLimiter(limiter)),
+		ConnectTimeout: *connectTimeout,
+		Concurrency:    *concurrency,
+		Legacy:         *legacy,
+		Bedrock:        *bedrock,
+		BedrockPorts:   raknetPorts,
+	}
+
+	if *exclude != "" {
+		scanner.Exclude = strings.Split(*exclude, ",")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results, err := scanner.Run(ctx)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	n, err := scan.WriteJSONL(os.Stdout, results)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Found %d open ports", n)
+}
+
+func parsePorts(s string) ([]uint16, error) {
+	var ports []uint16
+
+	for _, field := range strings.Split(s, ",") {
+		port, err := strconv.ParseUint(strings.TrimSpace(field), 10, 16)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", field, err)
+		}
+
+		ports = append(ports, uint16(port))
+	}
+
+	return ports, nil
+}
@@ -0,0 +1,88 @@
+// Package crypto implements the encryption layer of the Minecraft protocol.
+// See: https://wiki.vg/Protocol_Encryption
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+)
+
+// AES/CFB8 stream, which the standard library does not provide
+// as it only implements full block CFB.
+type cfb8 struct {
+	block   cipher.Block
+	iv      []byte
+	out     []byte
+	decrypt bool
+}
+
+// NewCFB8Encrypter returns a cipher.Stream which encrypts with
+// 8-bit cipher feedback mode, using the given Block and IV.
+func NewCFB8Encrypter(block cipher.Block, iv []byte) cipher.Stream {
+	return newCFB8(block, iv, false)
+}
+
+// NewCFB8Decrypter returns a cipher.Stream which decrypts with
+// 8-bit cipher feedback mode, using the given Block and IV.
+func NewCFB8Decrypter(block cipher.Block, iv []byte) cipher.Stream {
+	return newCFB8(block, iv, true)
+}
+
+func newCFB8(block cipher.Block, iv []byte, decrypt bool) cipher.Stream {
+	if len(iv) != block.BlockSize() {
+		panic("crypto: IV length must equal block size")
+	}
+
+	return &cfb8{
+		block:   block,
+		iv:      append([]byte(nil), iv...),
+		out:     make([]byte, block.BlockSize()),
+		decrypt: decrypt,
+	}
+}
+
+func (c *cfb8) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("crypto: output smaller than input")
+	}
+
+	for i, b := range src {
+		c.block.Encrypt(c.out, c.iv)
+
+		// The ciphertext byte is shifted into the IV
+		feedback := b
+		b ^= c.out[0]
+
+		if !c.decrypt {
+			feedback = b
+		}
+
+		copy(c.iv, c.iv[1:])
+		c.iv[len(c.iv)-1] = feedback
+
+		dst[i] = b
+	}
+}
+
+// Stream encrypts everything written to and decrypts everything
+// read from the wrapped io.ReadWriter.
+type Stream struct {
+	cipher.StreamReader
+	cipher.StreamWriter
+}
+
+// NewStream wraps rw in an AES/CFB8 Stream, using the shared
+// secret as both the key and the IV, like the Minecraft protocol.
+func NewStream(rw io.ReadWriter, secret []byte) (*Stream, error) {
+	block, err := aes.NewCipher(secret)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stream{
+		StreamReader: cipher.StreamReader{S: NewCFB8Decrypter(block, secret), R: rw},
+		StreamWriter: cipher.StreamWriter{S: NewCFB8Encrypter(block, secret), W: rw},
+	}, nil
+}
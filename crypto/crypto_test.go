@@ -0,0 +1,148 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"testing"
+)
+
+// NIST SP 800-38A, F.3.7 CFB8-AES128
+var (
+	cfb8Key        = mustDecodeHex("2b7e151628aed2a6abf7158809cf4f3c")
+	cfb8IV         = mustDecodeHex("000102030405060708090a0b0c0d0e0f")
+	cfb8Plaintext  = mustDecodeHex("6bc1bee22e409f96e93d7e117393172aae2d")
+	cfb8Ciphertext = mustDecodeHex("3b79424c9c0dd436bace9e0ed4586a4f32b9")
+)
+
+func TestCFB8Encrypter(t *testing.T) {
+	block, err := aes.NewCipher(cfb8Key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]byte, len(cfb8Plaintext))
+	NewCFB8Encrypter(block, cfb8IV).XORKeyStream(actual, cfb8Plaintext)
+
+	if !bytes.Equal(actual, cfb8Ciphertext) {
+		t.Errorf("Unexpected ciphertext: %x != %x", actual, cfb8Ciphertext)
+	}
+}
+
+func TestCFB8Decrypter(t *testing.T) {
+	block, err := aes.NewCipher(cfb8Key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Decrypt byte by byte, in place, to check the stream keeps its state
+	actual := append([]byte(nil), cfb8Ciphertext...)
+	stream := NewCFB8Decrypter(block, cfb8IV)
+
+	for i := range actual {
+		stream.XORKeyStream(actual[i:i+1], actual[i:i+1])
+	}
+
+	if !bytes.Equal(actual, cfb8Plaintext) {
+		t.Errorf("Unexpected plaintext: %x != %x", actual, cfb8Plaintext)
+	}
+}
+
+func TestStream(t *testing.T) {
+	secret := mustDecodeHex("000102030405060708090a0b0c0d0e0f")
+	expected := mustDecodeHex("67a135fa66dd0ad3533768c0c686561f")
+
+	var buff bytes.Buffer
+
+	stream, err := NewStream(&buff, secret)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = stream.Write([]byte("minecraft stream")); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buff.Bytes(), expected) {
+		t.Fatalf("Unexpected ciphertext: %x != %x", buff.Bytes(), expected)
+	}
+
+	actual := make([]byte, 16)
+
+	if _, err = stream.Read(actual); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(actual) != "minecraft stream" {
+		t.Errorf("Unexpected plaintext: %q", actual)
+	}
+}
+
+func TestServerHash(t *testing.T) {
+	tests := []struct {
+		Value    string
+		Expected string
+	}{
+		{Value: "Notch", Expected: "4ed1f46bbe04bc756bcb17c0c7ce3e4632f06a48"},
+		{Value: "jeb_", Expected: "-7c9d5b0044c130109a5d7b5fb5c317c02b4e28c1"},
+		{Value: "simon", Expected: "88e16a1019277b15d58faf0541e11910eb756f6"},
+	}
+
+	for _, test := range tests {
+		if actual := ServerHash(test.Value, nil, nil); actual != test.Expected {
+			t.Errorf("Unable to hash %s: %s != %s", test.Value, actual, test.Expected)
+		}
+	}
+}
+
+func TestEncryptWithPublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := GenerateSharedSecret()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := EncryptWithPublicKey(publicKey, secret)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decrypted, err := rsa.DecryptPKCS1v15(rand.Reader, key, encrypted)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decrypted, secret) {
+		t.Errorf("Unable to decrypt secret: %x != %x", decrypted, secret)
+	}
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
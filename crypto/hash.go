@@ -0,0 +1,30 @@
+package crypto
+
+import (
+	"crypto/sha1"
+	"math/big"
+)
+
+// ServerHash returns the hash the client and server send to the session server
+// to authenticate a login: the SHA-1 digest of the server ID, shared secret and
+// public key, formatted as a signed two's complement hexadecimal number.
+func ServerHash(serverID string, secret []byte, publicKey []byte) string {
+	h := sha1.New()
+	h.Write([]byte(serverID))
+	h.Write(secret)
+	h.Write(publicKey)
+
+	return hexDigest(h.Sum(nil))
+}
+
+// Formats a digest like Java's BigInteger.toString(16).
+func hexDigest(digest []byte) string {
+	n := new(big.Int).SetBytes(digest)
+
+	// Negative when the most significant bit is set
+	if digest[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(digest)*8)))
+	}
+
+	return n.Text(16)
+}
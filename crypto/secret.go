@@ -0,0 +1,59 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+)
+
+const (
+	// SharedSecretSize is the size of the AES-128 shared secret in bytes.
+	SharedSecretSize = 16
+)
+
+var (
+	// ErrNotRSAKey is returned when the public key from an
+	// Encryption Request is not an RSA key.
+	ErrNotRSAKey = errors.New("public key is not an RSA key")
+)
+
+// GenerateSharedSecret returns a random shared secret to encrypt the connection with.
+func GenerateSharedSecret() ([]byte, error) {
+	secret := make([]byte, SharedSecretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// ParsePublicKey parses the DER encoded public key sent in an Encryption Request.
+func ParsePublicKey(publicKey []byte) (*rsa.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+
+	if !ok {
+		return nil, ErrNotRSAKey
+	}
+
+	return rsaKey, nil
+}
+
+// EncryptWithPublicKey encrypts data, like the shared secret or the verify token,
+// with the DER encoded public key sent in an Encryption Request.
+func EncryptWithPublicKey(publicKey []byte, data []byte) ([]byte, error) {
+	key, err := ParsePublicKey(publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rsa.EncryptPKCS1v15(rand.Reader, key, data)
+}
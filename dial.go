@@ -0,0 +1,176 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// Delay before racing the next address, as recommended by RFC 8305
+	// when the Dialer has no FallbackDelay.
+	connectionAttemptDelay = 250 * time.Millisecond
+)
+
+// Connects to one of the IP addresses, racing them when HappyEyeballs is enabled.
+func (c *Client) dial(ctx context.Context, ips []net.IP, port uint16) (net.Conn, error) {
+	if c.HappyEyeballs && len(ips) > 1 {
+		return c.dialParallel(ctx, interleaveFamilies(ips), port)
+	}
+
+	return c.dialSerial(ctx, ips, port)
+}
+
+// Connects to the first of the IP addresses accepting the connection.
+func (c *Client) dialSerial(ctx context.Context, ips []net.IP, port uint16) (net.Conn, error) {
+	err := errors.New("no addresses")
+
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = c.dialIP(ctx, ip, port)
+
+		if err == nil {
+			return conn, nil
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, err
+}
+
+// Connects to the IP addresses in order, starting the next attempt when the
+// previous one failed or did not succeed within the connection attempt delay.
+// The first connection established wins, the others are closed.
+func (c *Client) dialParallel(ctx context.Context, ips []net.IP, port uint16) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+
+	delay := connectionAttemptDelay
+
+	if c.Dialer != nil && c.Dialer.FallbackDelay > 0 {
+		delay = c.Dialer.FallbackDelay
+	}
+
+	results := make(chan dialResult, len(ips))
+	pending, next := 0, 0
+	startNext := true
+	var attemptDelay <-chan time.Time
+	var err error
+
+	for next < len(ips) || pending > 0 {
+		if startNext && next < len(ips) {
+			go func(ip net.IP) {
+				conn, err := c.dialIP(ctx, ip, port)
+				results <- dialResult{conn: conn, err: err}
+			}(ips[next])
+
+			next++
+			pending++
+			startNext = false
+			attemptDelay = time.After(delay)
+		}
+
+		select {
+		case res := <-results:
+			pending--
+
+			if res.err == nil {
+				// Close connections of attempts still running
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if res := <-results; res.conn != nil {
+							res.conn.Close()
+						}
+					}
+				}(pending)
+
+				return res.conn, nil
+			}
+
+			err = res.err
+			startNext = true
+		case <-attemptDelay:
+			startNext = true
+		}
+	}
+
+	return nil, err
+}
+
+func (c *Client) dialIP(ctx context.Context, ip net.IP, port uint16) (net.Conn, error) {
+	trace := ContextPingTrace(ctx)
+	address := net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+
+	var release func()
+
+	if c.Limiter != nil {
+		var err error
+
+		if release, err = c.Limiter.Wait(ctx, ip); err != nil {
+			return nil, err
+		}
+	}
+
+	trace.connectStart("tcp", address)
+	conn, err := c.dialer().DialContext(ctx, "tcp", address)
+	trace.connectDone("tcp", address, err)
+
+	if c.Limiter == nil {
+		return conn, err
+	}
+
+	c.Limiter.Report(ip, err)
+
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return &limitedConn{Conn: conn, release: release}, nil
+}
+
+// Orders the IP addresses alternating between families,
+// starting with the family of the first address.
+func interleaveFamilies(ips []net.IP) []net.IP {
+	var first, second []net.IP
+	firstIsV4 := ips[0].To4() != nil
+
+	for _, ip := range ips {
+		if (ip.To4() != nil) == firstIsV4 {
+			first = append(first, ip)
+		} else {
+			second = append(second, ip)
+		}
+	}
+
+	ordered := make([]net.IP, 0, len(ips))
+
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			ordered = append(ordered, first[i])
+		}
+		if i < len(second) {
+			ordered = append(ordered, second[i])
+		}
+	}
+
+	return ordered
+}
+
+// Returns the address family of a TCP address, "tcp4" or "tcp6".
+func family(addr net.Addr) string {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok && tcpAddr.IP.To4() == nil {
+		return "tcp6"
+	}
+	return "tcp4"
+}
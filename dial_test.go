@@ -0,0 +1,91 @@
+package mcpinger
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestInterleaveFamilies(t *testing.T) {
+	ips := []net.IP{
+		net.ParseIP("2001:db8::1"),
+		net.ParseIP("2001:db8::2"),
+		net.ParseIP("2001:db8::3"),
+		net.ParseIP("192.0.2.1"),
+	}
+
+	expected := []net.IP{ips[0], ips[3], ips[1], ips[2]}
+
+	if actual := interleaveFamilies(ips); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected order: %v", actual)
+	}
+}
+
+func TestHappyEyeballs(t *testing.T) {
+	_, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	slow := net.ParseIP("127.0.0.2")
+
+	client := NewClient(WithHappyEyeballs(), WithDialer(&net.Dialer{
+		FallbackDelay: 20 * time.Millisecond,
+		// Delay connecting to the first address, like an unreachable network
+		Control: func(network, address string, c syscall.RawConn) error {
+			if host, _, _ := net.SplitHostPort(address); host == slow.String() {
+				time.Sleep(500 * time.Millisecond)
+			}
+			return nil
+		},
+	}))
+
+	start := time.Now()
+	res, err := client.query(context.Background(), "localhost", port, &resolved{
+		ips:  []net.IP{slow, net.ParseIP("127.0.0.1")},
+		port: port,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("Expected the second address to be raced, took %s", elapsed)
+	}
+
+	if res.Addr.(*net.TCPAddr).IP.String() != "127.0.0.1" || res.Family != "tcp4" {
+		t.Errorf("Unexpected address %s (%s)", res.Addr, res.Family)
+	}
+}
+
+func TestQueryAddrs(t *testing.T) {
+	_, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	// localhost resolves without a DNS server, the server only listens on IPv4
+	results, err := NewClient(WithTimeout(time.Second)).QueryAddrs(context.Background(), "localhost", port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+
+	for _, res := range results {
+		if res.IP.Equal(net.ParseIP("127.0.0.1")) {
+			found = true
+
+			if res.Err != nil {
+				t.Errorf("Unexpected error for %s: %v", res.IP, res.Err)
+			} else if res.Result.Info.Version.Name != "1.13.2" {
+				t.Errorf("Did not parse version name correctly for %s", res.IP)
+			}
+		} else if res.IP.To4() == nil && res.Err == nil {
+			t.Errorf("Expected an error for %s, as the server does not listen on it", res.IP)
+		}
+	}
+
+	if !found {
+		t.Errorf("Expected a result for 127.0.0.1, got %v", results)
+	}
+}
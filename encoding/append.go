@@ -0,0 +1,108 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Append-style encoders, which write into a caller provided buffer
+// instead of an io.Writer and do not allocate when it has enough capacity.
+
+// AppendUnsignedByte appends the passed UnsignedByte to the buffer
+func AppendUnsignedByte(b []byte, value UnsignedByte) []byte {
+	return append(b, byte(value))
+}
+
+// AppendByte appends the passed signed Byte to the buffer
+func AppendByte(b []byte, value Byte) []byte {
+	return append(b, byte(value))
+}
+
+// AppendBoolean appends the passed Boolean to the buffer
+func AppendBoolean(b []byte, value Boolean) []byte {
+	if value {
+		return append(b, 0x01)
+	}
+	return append(b, 0x00)
+}
+
+// AppendUnsignedShort appends the passed UnsignedShort to the buffer
+func AppendUnsignedShort(b []byte, value UnsignedShort) []byte {
+	return append(b, byte(value>>8), byte(value))
+}
+
+// AppendShort appends the passed Short to the buffer
+func AppendShort(b []byte, value Short) []byte {
+	return AppendUnsignedShort(b, UnsignedShort(value))
+}
+
+// AppendInt appends the passed Int to the buffer
+func AppendInt(b []byte, value Int) []byte {
+	var tmp [4]byte
+	binary.BigEndian.PutUint32(tmp[:], uint32(value))
+	return append(b, tmp[:]...)
+}
+
+// AppendLong appends the passed Long to the buffer
+func AppendLong(b []byte, value Long) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], uint64(value))
+	return append(b, tmp[:]...)
+}
+
+// AppendFloat appends the passed Float to the buffer
+func AppendFloat(b []byte, value Float) []byte {
+	return AppendInt(b, Int(math.Float32bits(float32(value))))
+}
+
+// AppendDouble appends the passed Double to the buffer
+func AppendDouble(b []byte, value Double) []byte {
+	return AppendLong(b, Long(math.Float64bits(float64(value))))
+}
+
+// AppendVarInt appends the passed VarInt encoded integer to the buffer
+func AppendVarInt(b []byte, value VarInt) []byte {
+	v := uint32(value)
+
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+
+	return append(b, byte(v))
+}
+
+// AppendVarLong appends the passed VarLong encoded integer to the buffer
+func AppendVarLong(b []byte, value VarLong) []byte {
+	v := uint64(value)
+
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+
+	return append(b, byte(v))
+}
+
+// AppendString appends a VarInt prefixed utf-8 string to the buffer
+func AppendString(b []byte, str String) []byte {
+	b = AppendVarInt(b, VarInt(len(str)))
+	return append(b, str...)
+}
+
+// AppendByteArray appends a VarInt length-prefixed byte array to the buffer
+func AppendByteArray(b []byte, data []byte) []byte {
+	b = AppendVarInt(b, VarInt(len(data)))
+	return append(b, data...)
+}
+
+// AppendUUID appends the passed UUID to the buffer
+func AppendUUID(b []byte, value UUID) []byte {
+	return append(b, value[:]...)
+}
+
+// AppendPosition appends the passed packed Position to the buffer
+func AppendPosition(b []byte, value Position) []byte {
+	packed := (int64(value.X)&0x3FFFFFF)<<38 | (int64(value.Z)&0x3FFFFFF)<<12 | int64(value.Y)&0xFFF
+	return AppendLong(b, Long(packed))
+}
@@ -0,0 +1,64 @@
+package encoding
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+)
+
+func TestAppendMatchesWrite(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Write  func(w io.Writer) error
+		Append func(b []byte) []byte
+	}{
+		{"VarInt", func(w io.Writer) error { return WriteVarInt(w, -2147483648) }, func(b []byte) []byte { return AppendVarInt(b, -2147483648) }},
+		{"VarLong", func(w io.Writer) error { return WriteVarLong(w, math.MinInt64) }, func(b []byte) []byte { return AppendVarLong(b, math.MinInt64) }},
+		{"UnsignedShort", func(w io.Writer) error { return WriteUnsignedShort(w, 25565) }, func(b []byte) []byte { return AppendUnsignedShort(b, 25565) }},
+		{"Short", func(w io.Writer) error { return WriteShort(w, math.MinInt16) }, func(b []byte) []byte { return AppendShort(b, math.MinInt16) }},
+		{"Int", func(w io.Writer) error { return WriteInt(w, -42) }, func(b []byte) []byte { return AppendInt(b, -42) }},
+		{"Long", func(w io.Writer) error { return WriteLong(w, math.MaxInt64) }, func(b []byte) []byte { return AppendLong(b, math.MaxInt64) }},
+		{"Float", func(w io.Writer) error { return WriteFloat(w, -2.25) }, func(b []byte) []byte { return AppendFloat(b, -2.25) }},
+		{"Double", func(w io.Writer) error { return WriteDouble(w, math.Pi) }, func(b []byte) []byte { return AppendDouble(b, math.Pi) }},
+		{"Boolean", func(w io.Writer) error { return WriteBoolean(w, true) }, func(b []byte) []byte { return AppendBoolean(b, true) }},
+		{"Byte", func(w io.Writer) error { return WriteByte(w, -1) }, func(b []byte) []byte { return AppendByte(b, -1) }},
+		{"String", func(w io.Writer) error { return WriteString(w, "😂😂😂") }, func(b []byte) []byte { return AppendString(b, "😂😂😂") }},
+		{"ByteArray", func(w io.Writer) error { return WriteByteArray(w, []byte{1, 2, 3}) }, func(b []byte) []byte { return AppendByteArray(b, []byte{1, 2, 3}) }},
+		{"Position", func(w io.Writer) error { return WritePosition(w, Position{X: 18357644, Y: 831, Z: -20882616}) }, func(b []byte) []byte { return AppendPosition(b, Position{X: 18357644, Y: 831, Z: -20882616}) }},
+	}
+
+	var buff bytes.Buffer
+
+	for _, test := range tests {
+		if err := test.Write(&buff); err != nil {
+			t.Fatal(err)
+		}
+
+		// Appending keeps existing buffer content
+		actual := test.Append([]byte{0xee})
+
+		if actual[0] != 0xee || !bytes.Equal(actual[1:], buff.Bytes()) {
+			t.Errorf("%s: Append does not match Write: %v != %v", test.Name, actual[1:], buff.Bytes())
+		}
+
+		buff.Reset()
+	}
+}
+
+func BenchmarkVarInt(b *testing.B) {
+	b.Run("write", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = WriteVarInt(io.Discard, -1)
+		}
+	})
+
+	b.Run("append", func(b *testing.B) {
+		buff := make([]byte, 0, VarIntMaxByteSize)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buff = AppendVarInt(buff[:0], -1)
+		}
+	})
+}
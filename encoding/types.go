@@ -1,19 +1,36 @@
 package encoding
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"io"
+	"math"
+	"strings"
 )
 
 const (
-	VarIntMaxByteSize = 5
+	VarIntMaxByteSize  = 5
+	VarLongMaxByteSize = 10
+
+	// Largest length-prefixed data allocated before reading it, longer data is
+	// only allocated as it arrives, so a bogus length can't exhaust memory.
+	maxPreallocSize = 64 * 1024
 )
 
 var (
 	// ErrVarIntTooLarge is returned when a read varint was too large
 	// (more than 5 bytes)
 	ErrVarIntTooLarge = errors.New("VarInt too large")
+
+	// ErrVarLongTooLarge is returned when a read varlong was too large
+	// (more than 10 bytes)
+	ErrVarLongTooLarge = errors.New("VarLong too large")
+
+	// ErrInvalidIdentifier is returned when an identifier contains
+	// characters which are not allowed
+	ErrInvalidIdentifier = errors.New("invalid identifier")
 )
 
 // Minecraft Protocol UnsignedShort type
@@ -34,9 +51,16 @@ func ReadUnsignedShort(buff io.Reader) (UnsignedShort, error) {
 // Minecrat Protocol UnsignedByte type
 type UnsignedByte byte
 
+// ReadUnsignedByte reads a single byte from the reader. Readers implementing
+// io.ByteReader, like bufio.Reader, are read from without allocating.
 func ReadUnsignedByte(r io.Reader) (UnsignedByte, error) {
+	if br, ok := r.(io.ByteReader); ok {
+		b, err := br.ReadByte()
+		return UnsignedByte(b), err
+	}
+
 	var bytes [1]byte
-	_, err := r.Read(bytes[:1])
+	_, err := io.ReadFull(r, bytes[:1])
 	return UnsignedByte(bytes[0]), err
 }
 
@@ -52,22 +76,9 @@ type VarInt int32
 
 // WriteVarInt writes the passed VarInt encoded integer to the writer.
 func WriteVarInt(w io.Writer, value VarInt) error {
-	for cont := true; cont; cont = value != 0 {
-		temp := byte(value & 0x7F)
-
-		// Casting value to a uint to get a logical shift
-		value = VarInt(uint32(value) >> 7)
-
-		if value != 0 {
-			temp |= 0x80
-		}
-
-		if err := WriteUnsignedByte(w, UnsignedByte(temp)); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	var buff [VarIntMaxByteSize]byte
+	_, err := w.Write(AppendVarInt(buff[:0], value))
+	return err
 }
 
 // ReadVarInt reads a VarInt encoded integer from the reader.
@@ -136,11 +147,400 @@ func ReadString(r io.Reader) (String, error) {
 		return "", errors.New("string cannot have a negative length")
 	}
 
-	// Creating string buffer with the specified size
-	stringBuff := make([]byte, int(l))
-
 	// Reading l amount of bytes from the buffer
-	_, err = io.ReadFull(r, stringBuff)
+	stringBuff, err := readBytes(r, int(l))
 
 	return String(stringBuff), err
 }
+
+// Minecraft Protocol Boolean type
+type Boolean bool
+
+// WriteBoolean writes the passed Boolean as a single byte to the writer
+func WriteBoolean(w io.Writer, value Boolean) error {
+	if value {
+		return WriteUnsignedByte(w, 0x01)
+	}
+	return WriteUnsignedByte(w, 0x00)
+}
+
+// ReadBoolean reads a single byte Boolean from the reader
+func ReadBoolean(r io.Reader) (Boolean, error) {
+	b, err := ReadUnsignedByte(r)
+	return b != 0x00, err
+}
+
+// Minecraft Protocol UUID type, encoded as an unsigned 128-bit integer
+type UUID [16]byte
+
+// ParseUUID parses a UUID in its textual form, with or without dashes
+func ParseUUID(s string) (UUID, error) {
+	var id UUID
+
+	s = strings.Replace(s, "-", "", -1)
+
+	if len(s) != 32 {
+		return id, errors.New("invalid UUID length")
+	}
+
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+// String returns the dashed textual form of the UUID
+func (id UUID) String() string {
+	h := hex.EncodeToString(id[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// WriteUUID writes the passed UUID to the writer
+func WriteUUID(w io.Writer, value UUID) error {
+	_, err := w.Write(value[:])
+	return err
+}
+
+// ReadUUID reads a UUID from the reader
+func ReadUUID(r io.Reader) (UUID, error) {
+	var id UUID
+	_, err := io.ReadFull(r, id[:])
+	return id, err
+}
+
+// WriteByteArray writes a VarInt length-prefixed byte array to the writer
+func WriteByteArray(w io.Writer, b []byte) error {
+	if err := WriteVarInt(w, VarInt(len(b))); err != nil {
+		return err
+	}
+
+	_, err := w.Write(b)
+	return err
+}
+
+// ReadByteArray reads a VarInt length-prefixed byte array from the
+// reader. It uses io.ReadFull to ensure all bytes are read.
+func ReadByteArray(r io.Reader) ([]byte, error) {
+	l, err := ReadVarInt(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if l < 0 {
+		return nil, errors.New("byte array cannot have a negative length")
+	}
+
+	return readBytes(r, int(l))
+}
+
+// Reads exactly l bytes, only allocating what arrives for large lengths.
+func readBytes(r io.Reader, l int) ([]byte, error) {
+	if l <= maxPreallocSize {
+		b := make([]byte, l)
+		_, err := io.ReadFull(r, b)
+		return b, err
+	}
+
+	var b bytes.Buffer
+	n, err := io.CopyN(&b, r, int64(l))
+
+	if n < int64(l) && err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return b.Bytes(), err
+}
+
+// VarIntSize returns the amount of bytes the passed value
+// occupies when VarInt encoded.
+func VarIntSize(value VarInt) int {
+	size := 1
+	for v := uint32(value) >> 7; v != 0; v >>= 7 {
+		size++
+	}
+	return size
+}
+
+// Minecraft Protocol Byte type
+type Byte int8
+
+// WriteByte writes the passed signed Byte to the writer
+func WriteByte(w io.Writer, value Byte) error {
+	return WriteUnsignedByte(w, UnsignedByte(value))
+}
+
+// ReadByte reads a signed Byte from the reader
+func ReadByte(r io.Reader) (Byte, error) {
+	b, err := ReadUnsignedByte(r)
+	return Byte(b), err
+}
+
+// Minecraft Protocol Short type
+type Short int16
+
+// WriteShort writes the passed Short to the writer
+func WriteShort(w io.Writer, value Short) error {
+	return binary.Write(w, binary.BigEndian, int16(value))
+}
+
+// ReadShort reads a Short from the reader
+func ReadShort(r io.Reader) (Short, error) {
+	var short int16
+	err := binary.Read(r, binary.BigEndian, &short)
+	return Short(short), err
+}
+
+// Minecraft Protocol Int type
+type Int int32
+
+// WriteInt writes the passed Int to the writer
+func WriteInt(w io.Writer, value Int) error {
+	return binary.Write(w, binary.BigEndian, int32(value))
+}
+
+// ReadInt reads an Int from the reader
+func ReadInt(r io.Reader) (Int, error) {
+	var i int32
+	err := binary.Read(r, binary.BigEndian, &i)
+	return Int(i), err
+}
+
+// Minecraft Protocol Long type
+type Long int64
+
+// WriteLong writes the passed Long to the writer
+func WriteLong(w io.Writer, value Long) error {
+	return binary.Write(w, binary.BigEndian, int64(value))
+}
+
+// ReadLong reads a Long from the reader
+func ReadLong(r io.Reader) (Long, error) {
+	var l int64
+	err := binary.Read(r, binary.BigEndian, &l)
+	return Long(l), err
+}
+
+// Minecraft Protocol Float type
+type Float float32
+
+// WriteFloat writes the passed IEEE 754 Float to the writer
+func WriteFloat(w io.Writer, value Float) error {
+	return binary.Write(w, binary.BigEndian, math.Float32bits(float32(value)))
+}
+
+// ReadFloat reads an IEEE 754 Float from the reader
+func ReadFloat(r io.Reader) (Float, error) {
+	var bits uint32
+	err := binary.Read(r, binary.BigEndian, &bits)
+	return Float(math.Float32frombits(bits)), err
+}
+
+// Minecraft Protocol Double type
+type Double float64
+
+// WriteDouble writes the passed IEEE 754 Double to the writer
+func WriteDouble(w io.Writer, value Double) error {
+	return binary.Write(w, binary.BigEndian, math.Float64bits(float64(value)))
+}
+
+// ReadDouble reads an IEEE 754 Double from the reader
+func ReadDouble(r io.Reader) (Double, error) {
+	var bits uint64
+	err := binary.Read(r, binary.BigEndian, &bits)
+	return Double(math.Float64frombits(bits)), err
+}
+
+// Minecraft Protocol VarLong type
+type VarLong int64
+
+// WriteVarLong writes the passed VarLong encoded integer to the writer.
+func WriteVarLong(w io.Writer, value VarLong) error {
+	var buff [VarLongMaxByteSize]byte
+	_, err := w.Write(AppendVarLong(buff[:0], value))
+	return err
+}
+
+// ReadVarLong reads a VarLong encoded integer from the reader.
+func ReadVarLong(r io.Reader) (VarLong, error) {
+	var numRead uint
+	var result int64
+	var read UnsignedByte
+
+	for cont := true; cont; cont = (read & 0x80) != 0 {
+		var err error
+		read, err = ReadUnsignedByte(r)
+
+		if err != nil {
+			return 0, err
+		}
+
+		value := read & 0x7F
+
+		result |= int64(value) << (7 * numRead)
+
+		numRead++
+
+		if numRead > VarLongMaxByteSize {
+			return 0, ErrVarLongTooLarge
+		}
+	}
+
+	return VarLong(result), nil
+}
+
+// Minecraft Protocol Position type, a block position packed
+// in a 64-bit integer (x: 26 bits, z: 26 bits, y: 12 bits)
+type Position struct {
+	X int32
+	Y int32
+	Z int32
+}
+
+// WritePosition writes the passed packed Position to the writer
+func WritePosition(w io.Writer, value Position) error {
+	var buff [8]byte
+	_, err := w.Write(AppendPosition(buff[:0], value))
+	return err
+}
+
+// ReadPosition reads a packed Position from the reader
+func ReadPosition(r io.Reader) (Position, error) {
+	packed, err := ReadLong(r)
+
+	if err != nil {
+		return Position{}, err
+	}
+
+	// Arithmetic shifts sign extend each component
+	return Position{
+		X: int32(packed >> 38),
+		Y: int32(packed << 52 >> 52),
+		Z: int32(packed << 26 >> 38),
+	}, nil
+}
+
+// Minecraft Protocol Identifier type, a namespaced location
+// like "minecraft:stone"
+type Identifier string
+
+// DefaultNamespace is the namespace of identifiers without one
+const DefaultNamespace = "minecraft"
+
+// Namespace returns the namespace of the Identifier
+func (id Identifier) Namespace() string {
+	if i := strings.IndexByte(string(id), ':'); i >= 0 {
+		return string(id[:i])
+	}
+	return DefaultNamespace
+}
+
+// Path returns the path (value) of the Identifier
+func (id Identifier) Path() string {
+	if i := strings.IndexByte(string(id), ':'); i >= 0 {
+		return string(id[i+1:])
+	}
+	return string(id)
+}
+
+// Valid returns whether the Identifier only contains allowed characters
+func (id Identifier) Valid() bool {
+	namespace, path := id.Namespace(), id.Path()
+
+	if namespace == "" || path == "" {
+		return false
+	}
+
+	return strings.Trim(namespace, "abcdefghijklmnopqrstuvwxyz0123456789_-.") == "" &&
+		strings.Trim(path, "abcdefghijklmnopqrstuvwxyz0123456789_-./") == ""
+}
+
+// WriteIdentifier writes the passed Identifier as a String to the writer
+func WriteIdentifier(w io.Writer, id Identifier) error {
+	if !id.Valid() {
+		return ErrInvalidIdentifier
+	}
+	return WriteString(w, String(id))
+}
+
+// ReadIdentifier reads an Identifier from the reader
+func ReadIdentifier(r io.Reader) (Identifier, error) {
+	str, err := ReadString(r)
+
+	if err != nil {
+		return "", err
+	}
+
+	id := Identifier(str)
+
+	if !id.Valid() {
+		return "", ErrInvalidIdentifier
+	}
+
+	return id, nil
+}
+
+// WriteOptional writes a Boolean telling whether the value is present,
+// followed by the value written by write when it is.
+func WriteOptional(w io.Writer, present bool, write func(w io.Writer) error) error {
+	if err := WriteBoolean(w, Boolean(present)); err != nil {
+		return err
+	}
+
+	if !present {
+		return nil
+	}
+
+	return write(w)
+}
+
+// ReadOptional reads a Boolean telling whether a value is present,
+// calling read to read the value when it is.
+func ReadOptional(r io.Reader, read func(r io.Reader) error) (bool, error) {
+	present, err := ReadBoolean(r)
+
+	if err != nil || !present {
+		return false, err
+	}
+
+	return true, read(r)
+}
+
+// WritePrefixedArray writes the VarInt length n, followed by the
+// n elements written by writeElem.
+func WritePrefixedArray(w io.Writer, n int, writeElem func(w io.Writer, i int) error) error {
+	if err := WriteVarInt(w, VarInt(n)); err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		if err := writeElem(w, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadPrefixedArray reads a VarInt length, calling readElem for each
+// of the n elements. It returns the amount of elements read.
+func ReadPrefixedArray(r io.Reader, readElem func(r io.Reader, n int, i int) error) (int, error) {
+	l, err := ReadVarInt(r)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if l < 0 {
+		return 0, errors.New("array cannot have a negative length")
+	}
+
+	for i := 0; i < int(l); i++ {
+		if err = readElem(r, int(l), i); err != nil {
+			return i, err
+		}
+	}
+
+	return int(l), nil
+}
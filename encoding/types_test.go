@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"math"
+	"runtime"
 	"testing"
 )
 
@@ -210,3 +211,363 @@ func TestReadString(t *testing.T) {
 		buff.Reset()
 	}
 }
+
+func TestUUIDRoundTrip(t *testing.T) {
+	id, err := ParseUUID("09bc745b-3679-4152-b96b-3f9c59c42059")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buff bytes.Buffer
+
+	if err = WriteUUID(&buff, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if buff.Len() != 16 {
+		t.Fatalf("UUID should be 16 bytes, got %d", buff.Len())
+	}
+
+	actual, err := ReadUUID(&buff)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual.String() != "09bc745b-3679-4152-b96b-3f9c59c42059" {
+		t.Errorf("Unable to round trip UUID: %s", actual)
+	}
+}
+
+func TestByteArrayRoundTrip(t *testing.T) {
+	tests := [][]byte{{}, {0x00}, {0x01, 0x02, 0x03}, bytes.Repeat([]byte{0xff}, 300)}
+
+	var buff bytes.Buffer
+
+	for _, test := range tests {
+		if err := WriteByteArray(&buff, test); err != nil {
+			t.Fatal(err)
+		}
+
+		if buff.Len() != VarIntSize(VarInt(len(test)))+len(test) {
+			t.Errorf("Unexpected encoded length %d for %d bytes", buff.Len(), len(test))
+		}
+
+		actual, err := ReadByteArray(&buff)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(actual, test) {
+			t.Errorf("Unable to round trip %v: %v", test, actual)
+		}
+
+		buff.Reset()
+	}
+}
+
+func TestByteArrayBogusLength(t *testing.T) {
+	// Announces 2 GiB, but only contains a few bytes
+	var buff bytes.Buffer
+	_ = WriteVarInt(&buff, math.MaxInt32)
+	buff.Write([]byte{0x01, 0x02, 0x03})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	if _, err := ReadByteArray(&buff); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	runtime.ReadMemStats(&after)
+
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Expected the announced length not to be allocated, allocated %d bytes", allocated)
+	}
+}
+
+func TestVarLong(t *testing.T) {
+	tests := []struct {
+		Value VarLong
+		Bytes []byte
+	}{
+		{Value: 0, Bytes: []byte{0x00}},
+		{Value: 1, Bytes: []byte{0x01}},
+		{Value: 127, Bytes: []byte{0x7f}},
+		{Value: 128, Bytes: []byte{0x80, 0x01}},
+		{Value: 2147483647, Bytes: []byte{0xff, 0xff, 0xff, 0xff, 0x07}},
+		{Value: 9223372036854775807, Bytes: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+		{Value: -1, Bytes: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{Value: -2147483648, Bytes: []byte{0x80, 0x80, 0x80, 0x80, 0xf8, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{Value: -9223372036854775808, Bytes: []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+	}
+
+	var buff bytes.Buffer
+
+	for _, test := range tests {
+		if err := WriteVarLong(&buff, test.Value); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buff.Bytes(), test.Bytes) {
+			t.Errorf("Unable to convert %d: %v != %v", test.Value, buff.Bytes(), test.Bytes)
+		}
+
+		actual, err := ReadVarLong(&buff)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != test.Value {
+			t.Errorf("Unable to convert %v: %d != %d", test.Bytes, actual, test.Value)
+		}
+
+		buff.Reset()
+	}
+}
+
+func TestReadVarLongTooLarge(t *testing.T) {
+	value := bytes.Repeat([]byte{0xff}, 11)
+
+	if _, err := ReadVarLong(bytes.NewReader(value)); err != ErrVarLongTooLarge {
+		t.Errorf("Expected ErrVarLongTooLarge, got %v", err)
+	}
+}
+
+func TestFixedSizeRoundTrip(t *testing.T) {
+	var buff bytes.Buffer
+
+	for _, value := range []Byte{0, 1, -1, math.MaxInt8, math.MinInt8} {
+		if err := WriteByte(&buff, value); err != nil {
+			t.Fatal(err)
+		}
+		if actual, err := ReadByte(&buff); err != nil || actual != value {
+			t.Errorf("Unable to round trip Byte %d: %d (%v)", value, actual, err)
+		}
+	}
+
+	for _, value := range []Short{0, 1, -1, math.MaxInt16, math.MinInt16} {
+		if err := WriteShort(&buff, value); err != nil {
+			t.Fatal(err)
+		}
+		if buff.Len() != 2 {
+			t.Errorf("Short should be 2 bytes, got %d", buff.Len())
+		}
+		if actual, err := ReadShort(&buff); err != nil || actual != value {
+			t.Errorf("Unable to round trip Short %d: %d (%v)", value, actual, err)
+		}
+	}
+
+	for _, value := range []Int{0, 1, -1, math.MaxInt32, math.MinInt32} {
+		if err := WriteInt(&buff, value); err != nil {
+			t.Fatal(err)
+		}
+		if buff.Len() != 4 {
+			t.Errorf("Int should be 4 bytes, got %d", buff.Len())
+		}
+		if actual, err := ReadInt(&buff); err != nil || actual != value {
+			t.Errorf("Unable to round trip Int %d: %d (%v)", value, actual, err)
+		}
+	}
+
+	for _, value := range []Long{0, 1, -1, math.MaxInt64, math.MinInt64} {
+		if err := WriteLong(&buff, value); err != nil {
+			t.Fatal(err)
+		}
+		if buff.Len() != 8 {
+			t.Errorf("Long should be 8 bytes, got %d", buff.Len())
+		}
+		if actual, err := ReadLong(&buff); err != nil || actual != value {
+			t.Errorf("Unable to round trip Long %d: %d (%v)", value, actual, err)
+		}
+	}
+
+	for _, value := range []Float{0, 1.5, -2.25, math.MaxFloat32, math.SmallestNonzeroFloat32} {
+		if err := WriteFloat(&buff, value); err != nil {
+			t.Fatal(err)
+		}
+		if actual, err := ReadFloat(&buff); err != nil || actual != value {
+			t.Errorf("Unable to round trip Float %f: %f (%v)", value, actual, err)
+		}
+	}
+
+	for _, value := range []Double{0, 1.5, -2.25, math.MaxFloat64, math.SmallestNonzeroFloat64} {
+		if err := WriteDouble(&buff, value); err != nil {
+			t.Fatal(err)
+		}
+		if actual, err := ReadDouble(&buff); err != nil || actual != value {
+			t.Errorf("Unable to round trip Double %f: %f (%v)", value, actual, err)
+		}
+	}
+
+	for _, value := range []Boolean{true, false} {
+		if err := WriteBoolean(&buff, value); err != nil {
+			t.Fatal(err)
+		}
+		if actual, err := ReadBoolean(&buff); err != nil || actual != value {
+			t.Errorf("Unable to round trip Boolean %t: %t (%v)", value, actual, err)
+		}
+	}
+}
+
+func TestPosition(t *testing.T) {
+	tests := []struct {
+		Value Position
+		Bytes []byte
+	}{
+		{Value: Position{X: 18357644, Y: 831, Z: -20882616}, Bytes: []byte{0x46, 0x07, 0x63, 0x2c, 0x15, 0xb4, 0x83, 0x3f}},
+		{Value: Position{X: 0, Y: 0, Z: 0}, Bytes: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{Value: Position{X: -1, Y: -1, Z: -1}, Bytes: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{Value: Position{X: -33554432, Y: -2048, Z: 33554431}, Bytes: []byte{0x80, 0x00, 0x00, 0x1f, 0xff, 0xff, 0xf8, 0x00}},
+	}
+
+	var buff bytes.Buffer
+
+	for _, test := range tests {
+		if err := WritePosition(&buff, test.Value); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buff.Bytes(), test.Bytes) {
+			t.Errorf("Unable to convert %+v: %v != %v", test.Value, buff.Bytes(), test.Bytes)
+		}
+
+		actual, err := ReadPosition(&buff)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != test.Value {
+			t.Errorf("Unable to convert %v: %+v != %+v", test.Bytes, actual, test.Value)
+		}
+
+		buff.Reset()
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		Value     Identifier
+		Namespace string
+		Path      string
+		Valid     bool
+	}{
+		{Value: "minecraft:stone", Namespace: "minecraft", Path: "stone", Valid: true},
+		{Value: "stone", Namespace: "minecraft", Path: "stone", Valid: true},
+		{Value: "velocity:player_info", Namespace: "velocity", Path: "player_info", Valid: true},
+		{Value: "fml:loginwrapper/handshake", Namespace: "fml", Path: "loginwrapper/handshake", Valid: true},
+		{Value: "Minecraft:Stone", Namespace: "Minecraft", Path: "Stone", Valid: false},
+		{Value: "bad/namespace:path", Namespace: "bad/namespace", Path: "path", Valid: false},
+		{Value: ":path", Namespace: "", Path: "path", Valid: false},
+	}
+
+	var buff bytes.Buffer
+
+	for _, test := range tests {
+		if test.Value.Namespace() != test.Namespace || test.Value.Path() != test.Path {
+			t.Errorf("Unable to split %s: %s, %s", test.Value, test.Value.Namespace(), test.Value.Path())
+		}
+
+		err := WriteIdentifier(&buff, test.Value)
+
+		if !test.Valid {
+			if err != ErrInvalidIdentifier {
+				t.Errorf("Expected ErrInvalidIdentifier for %s, got %v", test.Value, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := ReadIdentifier(&buff)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != test.Value {
+			t.Errorf("Unable to round trip %s: %s", test.Value, actual)
+		}
+
+		buff.Reset()
+	}
+}
+
+func TestOptional(t *testing.T) {
+	var buff bytes.Buffer
+
+	for _, present := range []bool{true, false} {
+		err := WriteOptional(&buff, present, func(w io.Writer) error {
+			return WriteVarInt(w, 42)
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var value VarInt
+
+		actual, err := ReadOptional(&buff, func(r io.Reader) error {
+			value, err = ReadVarInt(r)
+			return err
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != present || (present && value != 42) {
+			t.Errorf("Unable to round trip optional (present: %t): %t, %d", present, actual, value)
+		}
+
+		if buff.Len() != 0 {
+			t.Errorf("Unread bytes after optional: %v", buff.Bytes())
+		}
+	}
+}
+
+func TestPrefixedArray(t *testing.T) {
+	expected := []String{"foo", "bar", "baz"}
+
+	var buff bytes.Buffer
+
+	err := WritePrefixedArray(&buff, len(expected), func(w io.Writer, i int) error {
+		return WriteString(w, expected[i])
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual []String
+
+	n, err := ReadPrefixedArray(&buff, func(r io.Reader, n int, i int) error {
+		if actual == nil {
+			actual = make([]String, 0, n)
+		}
+
+		str, err := ReadString(r)
+		actual = append(actual, str)
+		return err
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != len(expected) || len(actual) != len(expected) {
+		t.Fatalf("Unexpected array length %d", n)
+	}
+
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("Unable to round trip element %d: %s != %s", i, actual[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package mcpinger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Software is the server software a server runs.
+type Software string
+
+const (
+	SoftwareUnknown    Software = "Unknown"
+	SoftwareVanilla    Software = "Vanilla"
+	SoftwarePaper      Software = "Paper"
+	SoftwarePurpur     Software = "Purpur"
+	SoftwareFabric     Software = "Fabric"
+	SoftwareForge      Software = "Forge"
+	SoftwareNeoForge   Software = "NeoForge"
+	SoftwareVelocity   Software = "Velocity"
+	SoftwareBungeeCord Software = "BungeeCord"
+	SoftwareGeyser     Software = "Geyser"
+)
+
+// FingerprintProtoVersion is the real protocol version (1.8) sent to check whether a server
+// echoes the client's version, which proxies & ViaVersion do while vanilla servers send their own.
+const FingerprintProtoVersion = 47
+
+// Software classified, in order of preference on equal scores.
+var softwares = []Software{
+	SoftwareVanilla, SoftwarePaper, SoftwarePurpur, SoftwareFabric, SoftwareForge,
+	SoftwareNeoForge, SoftwareVelocity, SoftwareBungeeCord, SoftwareGeyser,
+}
+
+// Software based on the vanilla server, which answers pings itself
+var vanillaFamily = []Software{
+	SoftwareVanilla, SoftwarePaper, SoftwarePurpur, SoftwareFabric, SoftwareForge, SoftwareNeoForge,
+}
+
+var proxies = []Software{SoftwareVelocity, SoftwareBungeeCord}
+
+var (
+	vanillaVersionName = regexp.MustCompile(`^\d+\.\d+(\.\d+)?(-(pre|rc)\d+)?$`)
+	versionRangeName   = regexp.MustCompile(`\d+\.\d+(\.\d+)?\.?x?\s*-\s*\d+\.\d+`)
+)
+
+// Version name prefixes, matched case insensitively, checked in order
+var versionNames = []struct {
+	prefix   string
+	software Software
+	weight   float64
+}{
+	{"paper", SoftwarePaper, 4},
+	{"folia", SoftwarePaper, 3},
+	{"purpur", SoftwarePurpur, 4},
+	{"pufferfish", SoftwarePurpur, 1},
+	{"spigot", SoftwarePaper, 1},
+	{"craftbukkit", SoftwarePaper, 1},
+	{"velocity", SoftwareVelocity, 4},
+	{"bungeecord", SoftwareBungeeCord, 4},
+	{"waterfall", SoftwareBungeeCord, 4},
+	{"flamecord", SoftwareBungeeCord, 3},
+	{"neoforge", SoftwareNeoForge, 4},
+	{"forge", SoftwareForge, 3},
+	{"fabric", SoftwareFabric, 3},
+	{"quilt", SoftwareFabric, 2},
+	{"geyser", SoftwareGeyser, 4},
+}
+
+// Observations are the responses of a server to the probes of Fingerprint.
+type Observations struct {
+	Status *ServerInfo // Response to a ping with UnknownProtoVersion
+
+	Versioned *ServerInfo // Response to a ping with VersionedProtocol, nil when not probed
+	// Protocol version sent for Versioned
+	VersionedProtocol int32
+
+	LegacyProbed bool        // Whether the legacy ping was sent
+	Legacy       *LegacyInfo // Response to the legacy ping, nil when not answered
+
+	Bedrock *BedrockInfo // Response to a Bedrock ping on the same host, nil when not answered
+}
+
+// Fingerprint is a guess of the software a server runs.
+type Fingerprint struct {
+	Software   Software             // Most likely software, SoftwareUnknown without any evidence
+	Confidence float64              // Probability of Software from 0 to 1
+	Scores     map[Software]float64 // Probability of each software
+	Evidence   []string             // Observations the guess is based on
+}
+
+// Fingerprint probes a server and classifies the software it runs.
+// The server is pinged with UnknownProtoVersion & FingerprintProtoVersion,
+// and with the legacy ping. A Bedrock ping is sent to the default Bedrock port,
+// as Geyser can only be told apart by also serving Bedrock Edition.
+// Only the first ping has to succeed.
+func (c *Client) Fingerprint(ctx context.Context, host string, port uint16) (*Fingerprint, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Probes bypass the cache, as its results are for the client's protocol version
+	probe := *c
+	probe.Cache = nil
+
+	probe.ProtoVersion = UnknownProtoVersion
+	status, err := probe.Ping(ctx, host, port)
+
+	if err != nil {
+		return nil, err
+	}
+
+	obs := Observations{Status: status, VersionedProtocol: FingerprintProtoVersion, LegacyProbed: true}
+
+	probe.ProtoVersion = FingerprintProtoVersion
+	obs.Versioned, _ = probe.Ping(ctx, host, port)
+	obs.Legacy, _ = probe.PingLegacy(ctx, host, port)
+
+	bedrockCtx, cancel := context.WithTimeout(ctx, time.Second)
+	obs.Bedrock, _ = probe.PingBedrock(bedrockCtx, host, DefaultBedrockPort)
+	cancel()
+
+	return Classify(obs), nil
+}
+
+// Classify classifies the software of a server from its responses.
+func Classify(obs Observations) *Fingerprint {
+	scores := make(map[Software]float64, len(softwares))
+	var evidence []string
+
+	add := func(weight float64, reason string, candidates ...Software) {
+		for _, s := range candidates {
+			scores[s] += weight
+		}
+		evidence = append(evidence, reason)
+	}
+
+	if info := obs.Status; info != nil {
+		classifyVersionName(info.Version.Name, add)
+		classifyFields(info, add)
+		classifyKeyOrder(info.Raw, add)
+
+		if obs.Versioned != nil && obs.Versioned.Version.Protocol == obs.VersionedProtocol &&
+			info.Version.Protocol != obs.VersionedProtocol {
+			add(1.5, "echoes the client's protocol version", SoftwareVelocity, SoftwareBungeeCord)
+			add(0.5, "echoes the client's protocol version, as with ViaVersion", SoftwarePaper, SoftwarePurpur)
+		}
+
+		if obs.LegacyProbed && obs.Legacy == nil {
+			add(-1, "does not answer the legacy ping", vanillaFamily...)
+		}
+	}
+
+	if obs.Bedrock != nil {
+		if obs.Status != nil {
+			add(2, "also serves Bedrock Edition", SoftwareGeyser)
+		}
+
+		if strings.Contains(strings.ToLower(obs.Bedrock.MOTD+" "+obs.Bedrock.SubMOTD), "geyser") {
+			add(2, "Bedrock MOTD mentions Geyser", SoftwareGeyser)
+		}
+	}
+
+	return fingerprintFromScores(scores, evidence)
+}
+
+// Converts the scores to probabilities with a softmax over all softwares.
+func fingerprintFromScores(scores map[Software]float64, evidence []string) *Fingerprint {
+	f := &Fingerprint{Software: SoftwareUnknown, Scores: make(map[Software]float64, len(softwares)), Evidence: evidence}
+
+	total := 0.0
+
+	for _, s := range softwares {
+		total += math.Exp(scores[s])
+	}
+
+	best := softwares[0]
+
+	for _, s := range softwares {
+		f.Scores[s] = math.Exp(scores[s]) / total
+
+		if f.Scores[s] > f.Scores[best] {
+			best = s
+		}
+	}
+
+	if len(evidence) > 0 {
+		f.Software, f.Confidence = best, f.Scores[best]
+	}
+
+	return f
+}
+
+func classifyVersionName(name string, add func(float64, string, ...Software)) {
+	lower := strings.ToLower(strings.TrimSpace(name))
+
+	for _, v := range versionNames {
+		if strings.HasPrefix(lower, v.prefix) {
+			add(v.weight, "version name starts with "+v.prefix, v.software)
+			return
+		}
+	}
+
+	switch {
+	case vanillaVersionName.MatchString(lower):
+		// Mod loaders keep the vanilla version name
+		add(2, "vanilla version name", SoftwareVanilla)
+		add(1, "vanilla version name", SoftwareFabric, SoftwareForge, SoftwareNeoForge)
+	case versionRangeName.MatchString(lower):
+		add(1.5, "version name is a version range", proxies...)
+	}
+}
+
+func classifyFields(info *ServerInfo, add func(float64, string, ...Software)) {
+	if info.IsModded {
+		add(4, "sends isModded", SoftwareNeoForge)
+	}
+
+	if info.ForgeData != nil {
+		// NeoForge for 1.20.1 still sends the Forge data
+		add(4, "sends forgeData", SoftwareForge)
+		add(2, "sends forgeData", SoftwareNeoForge)
+	}
+
+	if info.ModInfo != nil {
+		if len(info.ModInfo.ModList) == 0 {
+			// BungeeCord always sends empty mod info
+			add(3, "sends empty modinfo", SoftwareBungeeCord)
+		} else {
+			add(3, "sends modinfo with mods", SoftwareForge)
+		}
+	}
+
+	if info.PreventsChatReports != nil {
+		add(1, "sends preventsChatReports", SoftwareFabric, SoftwareForge, SoftwareNeoForge, SoftwarePaper, SoftwarePurpur)
+	}
+
+	// Vanilla servers send enforcesSecureChat since 1.19.1
+	const secureChatProtocol = 760
+
+	if info.EnforcesSecureChat != nil {
+		add(0.5, "sends enforcesSecureChat", vanillaFamily...)
+	} else if info.Version.Protocol >= secureChatProtocol {
+		add(1, "does not send enforcesSecureChat", proxies...)
+	}
+}
+
+// Vanilla servers write the description first, while proxies
+// serialize their ping class starting with the version.
+func classifyKeyOrder(raw json.RawMessage, add func(float64, string, ...Software)) {
+	keys := jsonKeys(raw)
+
+	if len(keys) == 0 {
+		return
+	}
+
+	switch keys[0] {
+	case "description":
+		add(1, "JSON starts with the description", vanillaFamily...)
+	case "version":
+		add(1, "JSON starts with the version", proxies...)
+	}
+}
+
+// Returns the keys of a JSON object in order, nil when not an object.
+func jsonKeys(raw json.RawMessage) []string {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+
+	var keys []string
+
+	for dec.More() {
+		tok, err := dec.Token()
+
+		if err != nil {
+			return keys
+		}
+
+		key, _ := tok.(string)
+		keys = append(keys, key)
+
+		var value json.RawMessage
+
+		if err = dec.Decode(&value); err != nil {
+			return keys
+		}
+	}
+
+	return keys
+}
+
+// Ranked returns the softwares from most to least likely.
+func (f *Fingerprint) Ranked() []Software {
+	ranked := make([]Software, len(softwares))
+	copy(ranked, softwares)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return f.Scores[ranked[i]] > f.Scores[ranked[j]]
+	})
+
+	return ranked
+}
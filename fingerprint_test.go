@@ -0,0 +1,142 @@
+package mcpinger
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	legacy := &LegacyInfo{Protocol: 127, Version: "1.21", Online: 0, Max: 20}
+
+	tests := []struct {
+		Name      string
+		Status    string
+		Versioned int32 // Protocol answered to FingerprintProtoVersion, zero when not probed
+		Legacy    *LegacyInfo
+		Bedrock   *BedrockInfo
+		Expected  Software
+	}{
+		{
+			Name:     "vanilla",
+			Status:   `{"description":{"text":"A Minecraft Server"},"players":{"max":20,"online":0},"version":{"name":"1.21","protocol":767},"enforcesSecureChat":true}`,
+			Legacy:   legacy,
+			Expected: SoftwareVanilla,
+		},
+		{
+			Name:     "paper",
+			Status:   `{"description":{"text":"A Minecraft Server"},"players":{"max":20,"online":0},"version":{"name":"Paper 1.21","protocol":767},"enforcesSecureChat":true}`,
+			Legacy:   legacy,
+			Expected: SoftwarePaper,
+		},
+		{
+			Name:     "purpur",
+			Status:   `{"description":{"text":"A Minecraft Server"},"players":{"max":20,"online":0},"version":{"name":"Purpur 1.21","protocol":767}}`,
+			Expected: SoftwarePurpur,
+		},
+		{
+			Name:     "fabric",
+			Status:   `{"description":{"text":"A Minecraft Server"},"players":{"max":20,"online":0},"version":{"name":"Fabric 1.21","protocol":767},"preventsChatReports":true}`,
+			Expected: SoftwareFabric,
+		},
+		{
+			Name:     "forge",
+			Status:   `{"description":{"text":"A Minecraft Server"},"players":{"max":20,"online":0},"version":{"name":"1.20.1","protocol":763},"forgeData":{"channels":[],"mods":[],"fmlNetworkVersion":3,"d":"abc"}}`,
+			Legacy:   legacy,
+			Expected: SoftwareForge,
+		},
+		{
+			Name:     "neoforge",
+			Status:   `{"description":{"text":"A Minecraft Server"},"players":{"max":20,"online":0},"version":{"name":"1.21","protocol":767},"isModded":true}`,
+			Legacy:   legacy,
+			Expected: SoftwareNeoForge,
+		},
+		{
+			Name:      "velocity",
+			Status:    `{"version":{"name":"Velocity 3.3.0","protocol":767},"players":{"max":500,"online":0},"description":{"text":"A Velocity Server"}}`,
+			Versioned: FingerprintProtoVersion,
+			Legacy:    legacy,
+			Expected:  SoftwareVelocity,
+		},
+		{
+			Name:      "customized bungeecord",
+			Status:    `{"version":{"name":"My Network 1.8.x-1.21.x","protocol":767},"players":{"max":500,"online":0},"description":{"text":"My Network"},"modinfo":{"type":"FML","modList":[]}}`,
+			Versioned: FingerprintProtoVersion,
+			Legacy:    legacy,
+			Expected:  SoftwareBungeeCord,
+		},
+		{
+			Name:     "geyser",
+			Status:   `{"description":{"text":"A Minecraft Server"},"players":{"max":20,"online":0},"version":{"name":"1.21","protocol":767},"enforcesSecureChat":true}`,
+			Legacy:   legacy,
+			Bedrock:  &BedrockInfo{Edition: "MCPE", MOTD: "Geyser", SubMOTD: "Another Geyser server."},
+			Expected: SoftwareGeyser,
+		},
+	}
+
+	for _, test := range tests {
+		info, err := parseServerInfo([]byte(test.Status))
+
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+
+		obs := Observations{Status: info, VersionedProtocol: FingerprintProtoVersion, LegacyProbed: true, Legacy: test.Legacy, Bedrock: test.Bedrock}
+
+		if test.Versioned != 0 {
+			obs.Versioned = &ServerInfo{Version: Version{Protocol: test.Versioned}}
+		}
+
+		f := Classify(obs)
+
+		if f.Software != test.Expected {
+			t.Errorf("%s: expected %s, got %s (%v, %v)", test.Name, test.Expected, f.Software, f.Scores, f.Evidence)
+		}
+
+		if f.Confidence <= 1.0/float64(len(softwares)) || f.Confidence > 1 {
+			t.Errorf("%s: unexpected confidence %f", test.Name, f.Confidence)
+		}
+
+		if ranked := f.Ranked(); ranked[0] != f.Software {
+			t.Errorf("%s: expected %s to be ranked first, got %v", test.Name, f.Software, ranked)
+		}
+	}
+
+	if f := Classify(Observations{}); f.Software != SoftwareUnknown || f.Confidence != 0 {
+		t.Errorf("Expected an unknown software without observations, got %s (%f)", f.Software, f.Confidence)
+	}
+}
+
+func TestJsonKeys(t *testing.T) {
+	keys := jsonKeys([]byte(`{"description":{"text":"a"},"players":{"sample":[{"name":"b"}]},"version":{"name":"1.21"}}`))
+
+	expected := []string{"description", "players", "version"}
+
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected keys %v, got %v", expected, keys)
+	}
+
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Expected keys %v, got %v", expected, keys)
+		}
+	}
+
+	if keys = jsonKeys([]byte(`"not an object"`)); keys != nil {
+		t.Errorf("Expected no keys of a string, got %v", keys)
+	}
+}
+
+func TestClientFingerprint(t *testing.T) {
+	host, port := serveStatus(t, GetTestFileContents(t, "info_description_1_20_3.json"))
+
+	f, err := NewClient(WithTimeout(500*time.Millisecond)).Fingerprint(context.Background(), host, port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Software != SoftwarePaper {
+		t.Errorf("Expected %s, got %s (%v)", SoftwarePaper, f.Software, f.Evidence)
+	}
+}
@@ -0,0 +1,67 @@
+package mcpinger
+
+// FML handshake markers, which Forge servers require to send their complete forgeData.
+// See: https://wiki.vg/Minecraft_Forge_Handshake
+const (
+	FMLNone = 0  // No marker
+	FML1    = 1  // \0FML\0, Forge 1.7 - 1.12.2
+	FML2    = 2  // \0FML2\0, Forge 1.13 - 1.17.1
+	FML3    = 3  // \0FML3\0, Forge 1.18+
+	FMLAuto = -1 // Marker matching the protocol version of the handshake
+)
+
+// Last protocol versions of each FML marker
+const (
+	fml1MaxProtocol = 340 // 1.12.2
+	fml2MinProtocol = 393 // 1.13
+	fml2MaxProtocol = 756 // 1.17.1
+)
+
+// WithFML appends an FML marker to the server address of the handshake,
+// so Forge servers send their complete mod list. version is FML1, FML2, FML3,
+// or FMLAuto to pick the marker by protocol version. Without WithProtocolVersion,
+// FMLAuto pings the server first to learn its version, then pings it with its marker.
+func WithFML(version int) McPingerOption {
+	return func(p *mcPinger) {
+		p.FML = version
+	}
+}
+
+// FMLMarker returns the FML marker for a protocol version,
+// empty for unknown versions and snapshots between 1.12.2 and 1.13.
+func FMLMarker(protoVer int32) string {
+	switch {
+	case protoVer < 0:
+		return ""
+	case protoVer <= fml1MaxProtocol:
+		return fmlMarker(FML1)
+	case protoVer >= fml2MinProtocol && protoVer <= fml2MaxProtocol:
+		return fmlMarker(FML2)
+	case protoVer > fml2MaxProtocol:
+		return fmlMarker(FML3)
+	default:
+		return ""
+	}
+}
+
+func fmlMarker(version int) string {
+	switch version {
+	case FML1:
+		return "\x00FML\x00"
+	case FML2:
+		return "\x00FML2\x00"
+	case FML3:
+		return "\x00FML3\x00"
+	default:
+		return ""
+	}
+}
+
+// Returns the server address of the handshake, with the FML marker of the client.
+func (c *Client) handshakeAddress(host string, protoVer int32) string {
+	if c.FML == FMLAuto {
+		return host + FMLMarker(protoVer)
+	}
+
+	return host + fmlMarker(c.FML)
+}
@@ -0,0 +1,74 @@
+package mcpinger
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Raqbit/mc-pinger/internal/mctest"
+)
+
+func TestFMLMarker(t *testing.T) {
+	tests := []struct {
+		Protocol int32
+		Expected string
+	}{
+		{UnknownProtoVersion, ""},
+		{47, "\x00FML\x00"},
+		{340, "\x00FML\x00"},
+		{350, ""},
+		{393, "\x00FML2\x00"},
+		{756, "\x00FML2\x00"},
+		{757, "\x00FML3\x00"},
+		{767, "\x00FML3\x00"},
+	}
+
+	for _, test := range tests {
+		if actual := FMLMarker(test.Protocol); actual != test.Expected {
+			t.Errorf("Unexpected marker for protocol %d, expected %q, got %q", test.Protocol, test.Expected, actual)
+		}
+	}
+}
+
+func TestWithFML(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Options  []McPingerOption
+		Expected []string // Handshake address of each connection
+	}{
+		{"none", nil, []string{"127.0.0.1"}},
+		{"explicit", []McPingerOption{WithFML(FML2)}, []string{"127.0.0.1\x00FML2\x00"}},
+		{"auto", []McPingerOption{WithFML(FMLAuto), WithProtocolVersion(763)}, []string{"127.0.0.1\x00FML3\x00"}},
+		// The server reports 1.13.2, so it is pinged again with FML2
+		{"auto without version", []McPingerOption{WithFML(FMLAuto)}, []string{"127.0.0.1", "127.0.0.1\x00FML2\x00"}},
+	}
+
+	info := GetTestFileContents(t, "info.json")
+
+	for _, test := range tests {
+		addresses := make(chan string, 2)
+
+		host, port, _ := mctest.Serve(t, func(conn net.Conn, hs mctest.Handshake) {
+			addresses <- hs.Address
+			_, _ = conn.Write(statusReply(info))
+		})
+
+		options := append([]McPingerOption{WithTimeout(time.Second)}, test.Options...)
+
+		if _, err := NewClient(options...).Ping(context.Background(), host, port); err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+
+		if len(addresses) != len(test.Expected) {
+			t.Errorf("%s: expected %d pings, got %d", test.Name, len(test.Expected), len(addresses))
+			continue
+		}
+
+		for _, expected := range test.Expected {
+			if actual := <-addresses; actual != expected {
+				t.Errorf("%s: expected handshake address %q, got %q", test.Name, expected, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	enc "github.com/Raqbit/mc-pinger/encoding"
+)
+
+const (
+	// VelocityForwardingChannel is the login plugin channel of Velocity modern forwarding.
+	VelocityForwardingChannel = "velocity:player_info"
+
+	// ForgedClientIP is the player address sent in a forged BungeeCord handshake (TEST-NET-1).
+	ForgedClientIP = "192.0.2.1"
+)
+
+// ErrProtocolRefused is returned when a server refuses to log in with the protocol version
+// of the probe, which says nothing about its forwarding.
+var ErrProtocolRefused = errors.New("server refused the protocol version")
+
+// UUID sent in a forged BungeeCord handshake, which a server only assigns when it trusts the handshake
+var forgedUUID = enc.UUID{0x6d, 0x63, 0x70, 0x69, 0x6e, 0x67, 0x40, 0x72, 0x80, 0x66, 0x6f, 0x72, 0x67, 0x65, 0x64, 0x21}
+
+// Forwarding is the kind of player info forwarding a backend server expects from its proxy.
+type Forwarding int
+
+const (
+	ForwardingNone       Forwarding = iota // Server does not expect forwarded player info
+	ForwardingBungeeCord                   // BungeeCord (or Velocity legacy) forwarding in the handshake address
+	ForwardingVelocity                     // Velocity modern forwarding, signed with a secret
+)
+
+func (f Forwarding) String() string {
+	switch f {
+	case ForwardingNone:
+		return "none"
+	case ForwardingBungeeCord:
+		return "BungeeCord"
+	case ForwardingVelocity:
+		return "Velocity"
+	default:
+		return fmt.Sprintf("Forwarding(%d)", int(f))
+	}
+}
+
+// ForwardingReport describes whether a backend server can be joined bypassing its proxy.
+type ForwardingReport struct {
+	Direct    *LoginResult // Answer to a plain login, past Set Compression
+	Forged    *LoginResult // Answer to a login with a forged BungeeCord handshake past Set Compression, nil when it failed
+	ForgedErr error        // Error of the forged login
+
+	Forwarding Forwarding // Forwarding the server expects
+	Exposed    bool       // Whether players can join with any name or UUID, bypassing the proxy
+	Reason     string     // Explanation of the verdict
+}
+
+// CheckForwarding probes the login state of a backend server directly and with a forged
+// BungeeCord handshake, reporting whether it is exposed to forwarding spoofing.
+// A backend behind a proxy should refuse both: a backend accepting the forged handshake
+// trusts any player info, and an offline mode backend accepting direct logins trusts any name.
+// ErrProtocolRefused is returned when the server refuses the protocol version of the logins.
+func (c *Client) CheckForwarding(ctx context.Context, host string, port uint16) (*ForwardingReport, error) {
+	protoVer := c.loginProtoVersion(ctx, host, port)
+	direct, err := c.probeLogin(ctx, host, port, protoVer, c.handshakeAddress(host, protoVer), true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if refusedProtocol(direct) {
+		return nil, ErrProtocolRefused
+	}
+
+	report := &ForwardingReport{Direct: direct}
+	report.Forged, report.ForgedErr = c.probeLogin(ctx, host, port, protoVer, forgedHandshakeAddress(host), true)
+
+	if report.Forged != nil && refusedProtocol(report.Forged) {
+		report.Forged, report.ForgedErr = nil, ErrProtocolRefused
+	}
+
+	switch {
+	case report.Forged != nil && loggedIn(report.Forged) && report.Forged.UUID == forgedUUID.String():
+		report.Forwarding, report.Exposed = ForwardingBungeeCord, true
+		report.Reason = "accepts a forged BungeeCord handshake, so anyone can join as any player"
+	case direct.Outcome == LoginPluginRequest && direct.PluginChannel == VelocityForwardingChannel:
+		report.Forwarding = ForwardingVelocity
+		report.Reason = "requires Velocity modern forwarding, which is signed with the forwarding secret"
+	case direct.Outcome == LoginEncryptionRequest:
+		report.Reason = "authenticates players in online mode"
+	case loggedIn(direct):
+		report.Exposed = true
+		report.Reason = "accepts direct logins in offline mode, so anyone can join with any name"
+	case direct.Outcome == LoginDisconnect && mentionsForwarding(direct.Reason):
+		report.Forwarding = ForwardingBungeeCord
+		report.Reason = "expects BungeeCord forwarding, but refuses the forged handshake"
+	default:
+		report.Reason = "refuses direct logins"
+	}
+
+	return report, nil
+}
+
+// Returns the BungeeCord forwarding handshake address of a player at ForgedClientIP.
+// See: https://github.com/SpigotMC/BungeeCord/blob/master/proxy/src/main/java/net/md_5/bungee/ServerConnector.java
+func forgedHandshakeAddress(host string) string {
+	return host + "\x00" + ForgedClientIP + "\x00" + strings.ReplaceAll(forgedUUID.String(), "-", "")
+}
+
+// Reports whether a login got past the point where the server authenticates the player.
+func loggedIn(res *LoginResult) bool {
+	return res.Outcome == LoginSuccess
+}
+
+// Reports whether a login was refused for its protocol version, either with the
+// vanilla outdated translation or the plain text of older servers & proxies.
+func refusedProtocol(res *LoginResult) bool {
+	if res.Outcome != LoginDisconnect || res.Reason == nil {
+		return false
+	}
+
+	if strings.HasPrefix(res.Reason.Translate, "multiplayer.disconnect.outdated_") {
+		return true
+	}
+
+	return strings.HasPrefix(strings.ToLower(res.Reason.PlainText()), "outdated ")
+}
+
+// Reports whether a disconnect reason is the one of a server expecting IP forwarding.
+func mentionsForwarding(reason *ChatComponent) bool {
+	if reason == nil {
+		return false
+	}
+
+	text := strings.ToLower(reason.PlainText())
+
+	return strings.Contains(text, "forwarding") || strings.Contains(text, "bungeecord")
+}
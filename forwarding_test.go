@@ -0,0 +1,165 @@
+package mcpinger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"github.com/Raqbit/mc-pinger/internal/mctest"
+	"github.com/Raqbit/mc-pinger/packet"
+)
+
+func disconnectPacket(reason string) []byte {
+	return buildPacket(0x00, func(w io.Writer) {
+		_ = enc.WriteString(w, enc.String(`{"text":"`+reason+`"}`))
+	})
+}
+
+func loginSuccessPacket(id enc.UUID, name string) []byte {
+	return buildPacket(0x02, func(w io.Writer) {
+		_ = enc.WriteUUID(w, id)
+		_ = enc.WriteString(w, enc.String(name))
+		_ = enc.WriteVarInt(w, 0)
+	})
+}
+
+func TestCheckForwarding(t *testing.T) {
+	const forwardingReason = "If you wish to use IP forwarding, please enable it in your BungeeCord config as well!"
+
+	tests := []struct {
+		Name       string
+		Reply      func(address string) []byte
+		Forwarding Forwarding
+		Exposed    bool
+	}{
+		{
+			Name: "spoofable bungeecord",
+			Reply: func(address string) []byte {
+				parts := strings.Split(address, "\x00")
+
+				if len(parts) < 3 {
+					return disconnectPacket(forwardingReason)
+				}
+
+				id, _ := enc.ParseUUID(parts[2])
+
+				// Login Success follows Set Compression in the compressed format
+				var b bytes.Buffer
+				b.Write(buildPacket(0x03, func(w io.Writer) { _ = enc.WriteVarInt(w, 256) }))
+				_, _ = packet.NewCompressedWriter(&b, 256).Write(loginSuccessPacket(id, "Notch"))
+
+				return b.Bytes()
+			},
+			Forwarding: ForwardingBungeeCord,
+			Exposed:    true,
+		},
+		{
+			Name: "bungeeguard",
+			Reply: func(address string) []byte {
+				if strings.Contains(address, "\x00") {
+					return disconnectPacket("Unable to authenticate - no data was forwarded by the proxy.")
+				}
+
+				return disconnectPacket(forwardingReason)
+			},
+			Forwarding: ForwardingBungeeCord,
+		},
+		{
+			Name: "velocity",
+			Reply: func(string) []byte {
+				return buildPacket(0x04, func(w io.Writer) {
+					_ = enc.WriteVarInt(w, 1)
+					_ = enc.WriteString(w, VelocityForwardingChannel)
+					_, _ = w.Write([]byte{0x04})
+				})
+			},
+			Forwarding: ForwardingVelocity,
+		},
+		{
+			Name: "offline mode",
+			Reply: func(string) []byte {
+				return loginSuccessPacket(offlineUUID("Notch"), "Notch")
+			},
+			Forwarding: ForwardingNone,
+			Exposed:    true,
+		},
+		{
+			Name: "online mode",
+			Reply: func(string) []byte {
+				return buildPacket(0x01, func(w io.Writer) {
+					_ = enc.WriteString(w, "")
+					_ = enc.WriteByteArray(w, []byte{0x30, 0x81})
+					_ = enc.WriteByteArray(w, []byte{0x01, 0x02, 0x03, 0x04})
+					_ = enc.WriteBoolean(w, true)
+				})
+			},
+			Forwarding: ForwardingNone,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			host, port := serveLogins(t, []byte(`{"version":{"name":"1.21","protocol":767}}`), func(hs mctest.Handshake) []byte {
+				return test.Reply(hs.Address)
+			})
+
+			client := NewClient(WithTimeout(time.Second), WithUsername("Notch"))
+			report, err := client.CheckForwarding(context.Background(), host, port)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Forwarding != test.Forwarding || report.Exposed != test.Exposed {
+				t.Errorf("Expected %s forwarding and exposed %t, got %s and %t: %s",
+					test.Forwarding, test.Exposed, report.Forwarding, report.Exposed, report.Reason)
+			}
+		})
+	}
+}
+
+func TestCheckForwardingProtocol(t *testing.T) {
+	// Like vanilla, the server refuses logins of other versions than its own
+	reply := func(hs mctest.Handshake) []byte {
+		if hs.ProtoVer != 765 {
+			return buildPacket(0x00, func(w io.Writer) {
+				_ = enc.WriteString(w, `{"translate":"multiplayer.disconnect.outdated_client","with":["1.20.4"]}`)
+			})
+		}
+
+		return loginSuccessPacket(offlineUUID("Notch"), "Notch")
+	}
+
+	host, port := serveLogins(t, GetTestFileContents(t, "info_description_1_20_3.json"), reply)
+
+	client := NewClient(WithTimeout(time.Second), WithUsername("Notch"))
+	report, err := client.CheckForwarding(context.Background(), host, port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.Exposed {
+		t.Errorf("Expected the login with the reported protocol to be accepted, got %s", report.Reason)
+	}
+
+	// The version of the probe is refused, which is no verdict
+	client = NewClient(WithTimeout(time.Second), WithProtocolVersion(767))
+
+	if _, err = client.CheckForwarding(context.Background(), host, port); !errors.Is(err, ErrProtocolRefused) {
+		t.Errorf("Expected the protocol to be refused, got %v", err)
+	}
+}
+
+func TestForgedHandshakeAddress(t *testing.T) {
+	parts := strings.Split(forgedHandshakeAddress("mc.example.com"), "\x00")
+
+	if len(parts) != 3 || parts[0] != "mc.example.com" || parts[1] != ForgedClientIP || len(parts[2]) != 32 {
+		t.Errorf("Unexpected forged handshake address %q", parts)
+	}
+}
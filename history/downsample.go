@@ -0,0 +1,71 @@
+package history
+
+import (
+	"time"
+)
+
+// Bucket summarizes the records within an interval.
+type Bucket struct {
+	Start      time.Time
+	Samples    int
+	Uptime     float64       // Fraction of samples which were online
+	AvgPlayers float64       // Average players online, of online samples
+	MaxPlayers int32         // Peak players online
+	AvgLatency time.Duration // Average latency, of online samples
+}
+
+// Downsample summarizes records in buckets of the given interval, aligned to
+// the Unix epoch, for charting long ranges. Records must be sorted by time,
+// as returned by Store.Query. Intervals without records are left out.
+func Downsample(records []Record, interval time.Duration) []Bucket {
+	var buckets []Bucket
+
+	var cur *Bucket
+	var online int
+	var players int64
+	var latency time.Duration
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+
+		cur.Uptime = float64(online) / float64(cur.Samples)
+
+		if online > 0 {
+			cur.AvgPlayers = float64(players) / float64(online)
+			cur.AvgLatency = latency / time.Duration(online)
+		}
+
+		buckets = append(buckets, *cur)
+	}
+
+	for _, rec := range records {
+		start := rec.Time.Truncate(interval)
+
+		if cur == nil || !start.Equal(cur.Start) {
+			flush()
+
+			cur = &Bucket{Start: start}
+			online, players, latency = 0, 0, 0
+		}
+
+		cur.Samples++
+
+		if !rec.Online {
+			continue
+		}
+
+		online++
+		players += int64(rec.Players)
+		latency += rec.Latency
+
+		if rec.Players > cur.MaxPlayers {
+			cur.MaxPlayers = rec.Players
+		}
+	}
+
+	flush()
+
+	return buckets
+}
@@ -0,0 +1,145 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileStore is a Store appending records as JSON lines to a file.
+// It is safe for concurrent use.
+type FileStore struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenFile opens or creates a FileStore at path.
+func OpenFile(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Terminate a line torn by a crash, so the next record starts on a new line
+	if err = terminateLine(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &FileStore{file: file}, nil
+}
+
+// Append appends a record to the file.
+func (s *FileStore) Append(rec Record) error {
+	line, err := json.Marshal(rec)
+
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Single write, so records are never interleaved
+	if _, err = s.file.Write(line); err != nil {
+		return errors.New("could not append record: " + err.Error())
+	}
+
+	return nil
+}
+
+// Query scans the file for the records of target within [from, to), oldest first.
+// Lines which can't be decoded, like those torn by a crash, are skipped.
+func (s *FileStore) Query(target string, from, to time.Time) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.file.Name())
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var records []Record
+
+	rd := bufio.NewReader(f)
+
+	for {
+		line, err := rd.ReadBytes('\n')
+
+		if len(line) > 0 {
+			var rec Record
+
+			if json.Unmarshal(line, &rec) == nil && matches(rec, target, from, to) {
+				records = append(records, rec)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Records may be appended out of order, like by concurrent watchers
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+
+	return records, nil
+}
+
+// Close closes the file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func matches(rec Record, target string, from, to time.Time) bool {
+	if target != "" && rec.Target != target {
+		return false
+	}
+
+	if !from.IsZero() && rec.Time.Before(from) {
+		return false
+	}
+
+	return to.IsZero() || rec.Time.Before(to)
+}
+
+// Writes a newline if the file does not end with one.
+func terminateLine(file *os.File) error {
+	info, err := file.Stat()
+
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	last := make([]byte, 1)
+
+	if _, err = file.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(last, []byte{'\n'}) {
+		_, err = file.Write([]byte{'\n'})
+	}
+
+	return err
+}
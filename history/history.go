@@ -0,0 +1,122 @@
+// Package history stores ping results over time, for charting a server's players & uptime.
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"strconv"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+// Record is a stored ping result.
+type Record struct {
+	Time     time.Time     `json:"time"`
+	Target   string        `json:"target"` // Pinged host & port, like "example.com:25565"
+	Online   bool          `json:"online"`
+	Latency  time.Duration `json:"latency,omitempty"` // Round trip, or time to first byte when not measured
+	Players  int32         `json:"players,omitempty"`
+	Max      int32         `json:"max,omitempty"`
+	Version  string        `json:"version,omitempty"`
+	Protocol int32         `json:"protocol,omitempty"`
+	MOTD     string        `json:"motd,omitempty"`    // Hash of the description
+	Favicon  string        `json:"favicon,omitempty"` // Hash of the favicon, empty when there is none
+	Error    string        `json:"error,omitempty"`   // Error of the ping when offline
+}
+
+// Store stores records.
+type Store interface {
+	// Append stores a record.
+	Append(rec Record) error
+
+	// Query returns the records of target within [from, to), oldest first.
+	// An empty target matches all targets, a zero from or to is unbounded.
+	Query(target string, from, to time.Time) ([]Record, error)
+
+	// Close closes the store.
+	Close() error
+}
+
+// Target returns the target of host & port as stored in records.
+func Target(host string, port uint16) string {
+	return net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// NewRecord Creates a record of the result of a ping at time t.
+func NewRecord(target string, t time.Time, res *mcpinger.Result, err error) Record {
+	rec := Record{Time: t, Target: target}
+
+	if err != nil || res == nil || res.Info == nil {
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		return rec
+	}
+
+	info := res.Info
+
+	rec.Online = true
+	rec.Players = info.Players.Online
+	rec.Max = info.Players.Max
+	rec.Version = info.Version.Name
+	rec.Protocol = info.Version.Protocol
+
+	rec.Latency = res.Timing.RTT
+
+	if rec.Latency == 0 {
+		rec.Latency = res.Timing.FirstByte
+	}
+
+	if motd, err := json.Marshal(info.Description); err == nil {
+		rec.MOTD = hash(string(motd))
+	}
+
+	if info.Favicon != "" {
+		rec.Favicon = hash(info.Favicon)
+	}
+
+	return rec
+}
+
+// Recorder returns a hook appending a record of every result to store,
+// to use as the OnResult hook of a mcpinger.Watcher.
+// Errors of the store are passed to onError, if set.
+func Recorder(store Store, target string, onError func(error)) func(res *mcpinger.Result, err error) {
+	return func(res *mcpinger.Result, err error) {
+		if err := store.Append(NewRecord(target, time.Now(), res, err)); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// RecordAll appends a record of every result of a mcpinger.Client's QueryAll to store,
+// passing the results on to the returned channel, which is closed after results.
+// Errors of the store are passed to onError, if set.
+func RecordAll(store Store, results <-chan mcpinger.BulkResult, onError func(error)) <-chan mcpinger.BulkResult {
+	out := make(chan mcpinger.BulkResult, cap(results))
+
+	go func() {
+		defer close(out)
+
+		for result := range results {
+			target := Target(result.Target.Host, result.Target.Port)
+
+			if err := store.Append(NewRecord(target, time.Now(), result.Result, result.Err)); err != nil && onError != nil {
+				onError(err)
+			}
+
+			out <- result
+		}
+	}()
+
+	return out
+}
+
+// Returns a short hash of s, enough to tell changes apart.
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,206 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	store, err := OpenFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		target := "a.example.com:25565"
+		if i%2 == 1 {
+			target = "b.example.com:25565"
+		}
+
+		if err = store.Append(Record{Time: epoch.Add(time.Duration(i) * time.Minute), Target: target, Online: true, Players: int32(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store.Close()
+
+	// Tear the last line, like a crash while writing
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	_, _ = f.WriteString(`{"time":"2024-01-0`)
+	f.Close()
+
+	// Reopen and append after the torn line
+	if store, err = OpenFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	defer store.Close()
+
+	if err = store.Append(Record{Time: epoch.Add(10 * time.Minute), Target: "a.example.com:25565"}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.Query("a.example.com:25565", time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 3 || records[0].Players != 0 || records[1].Players != 2 || records[2].Online {
+		t.Errorf("Unexpected records %+v", records)
+	}
+
+	records, err = store.Query("", epoch.Add(time.Minute), epoch.Add(3*time.Minute))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 || records[0].Players != 1 || records[1].Players != 2 {
+		t.Errorf("Unexpected records within range %+v", records)
+	}
+}
+
+func TestNewRecord(t *testing.T) {
+	info := &mcpinger.ServerInfo{Version: mcpinger.Version{Name: "1.21", Protocol: 767}, Favicon: "data:image/png;base64,AA=="}
+	info.Players.Online = 5
+	info.Players.Max = 20
+	info.Description.Text = "Hello world"
+
+	res := &mcpinger.Result{Info: info, Timing: mcpinger.Timing{FirstByte: 20 * time.Millisecond}}
+
+	rec := NewRecord(Target("example.com", 25565), epoch, res, nil)
+
+	if rec.Target != "example.com:25565" || !rec.Online || rec.Players != 5 || rec.Latency != 20*time.Millisecond {
+		t.Errorf("Unexpected record %+v", rec)
+	}
+
+	if rec.MOTD == "" || rec.Favicon == "" {
+		t.Error("Expected MOTD and favicon hashes")
+	}
+
+	info.Description.Text = "Goodbye world"
+
+	if NewRecord(rec.Target, epoch, res, nil).MOTD == rec.MOTD {
+		t.Error("Expected the MOTD hash to change with the MOTD")
+	}
+
+	offline := NewRecord(rec.Target, epoch, nil, errors.New("connection refused"))
+
+	if offline.Online || offline.Error != "connection refused" {
+		t.Errorf("Unexpected offline record %+v", offline)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	records := []Record{
+		{Time: epoch, Online: true, Players: 2, Latency: 10 * time.Millisecond},
+		{Time: epoch.Add(20 * time.Minute), Online: true, Players: 6, Latency: 30 * time.Millisecond},
+		{Time: epoch.Add(40 * time.Minute)},
+		// Nothing within the second hour
+		{Time: epoch.Add(2 * time.Hour), Online: true, Players: 1},
+	}
+
+	buckets := Downsample(records, time.Hour)
+
+	if len(buckets) != 2 {
+		t.Fatalf("Expected 2 buckets, got %d", len(buckets))
+	}
+
+	b := buckets[0]
+
+	if !b.Start.Equal(epoch) || b.Samples != 3 || b.AvgPlayers != 4 || b.MaxPlayers != 6 || b.AvgLatency != 20*time.Millisecond {
+		t.Errorf("Unexpected bucket %+v", b)
+	}
+
+	if b.Uptime < 0.66 || b.Uptime > 0.67 {
+		t.Errorf("Expected an uptime of 2/3, got %f", b.Uptime)
+	}
+
+	if !buckets[1].Start.Equal(epoch.Add(2*time.Hour)) || buckets[1].Uptime != 1 {
+		t.Errorf("Unexpected bucket %+v", buckets[1])
+	}
+}
+
+func TestFileStoreOutOfOrder(t *testing.T) {
+	store, err := OpenFile(filepath.Join(t.TempDir(), "history.jsonl"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer store.Close()
+
+	for _, minute := range []int{2, 0, 1} {
+		if err = store.Append(Record{Time: epoch.Add(time.Duration(minute) * time.Minute), Players: int32(minute)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := store.Query("", time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, rec := range records {
+		if rec.Players != int32(i) {
+			t.Fatalf("Expected records oldest first, got %+v", records)
+		}
+	}
+}
+
+func TestRecordAll(t *testing.T) {
+	store, err := OpenFile(filepath.Join(t.TempDir(), "history.jsonl"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer store.Close()
+
+	info := new(mcpinger.ServerInfo)
+	info.Players.Online = 5
+
+	results := make(chan mcpinger.BulkResult, 2)
+	results <- mcpinger.BulkResult{Target: mcpinger.Target{Host: "a.example.com", Port: 25565}, Result: &mcpinger.Result{Info: info}}
+	results <- mcpinger.BulkResult{Target: mcpinger.Target{Host: "b.example.com", Port: 25565}, Err: errors.New("connection refused")}
+	close(results)
+
+	passed := 0
+
+	for range RecordAll(store, results, func(err error) { t.Error(err) }) {
+		passed++
+	}
+
+	if passed != 2 {
+		t.Errorf("Expected 2 results to be passed on, got %d", passed)
+	}
+
+	records, err := store.Query("", time.Time{}, time.Time{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %+v", records)
+	}
+
+	for _, rec := range records {
+		if rec.Target == "a.example.com:25565" && (!rec.Online || rec.Players != 5) ||
+			rec.Target == "b.example.com:25565" && (rec.Online || rec.Error == "") {
+			t.Errorf("Unexpected record %+v", rec)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort is the port used when a request or allowlist entry has none.
+const DefaultPort = 25565
+
+// Allowlist lists the servers which may be pinged, so the API can't be used as an open scanner.
+// Entries are hosts, optionally with a port, like "mc.example.com" or "10.0.0.1:25566".
+// Entries without a port only allow the default port, entries starting with "*." also
+// match all subdomains, and the entry "*" allows all servers.
+// An empty Allowlist allows nothing.
+type Allowlist []string
+
+// Allowed reports whether the server at host & port may be pinged.
+func (a Allowlist) Allowed(host string, port uint16) bool {
+	host = normalizeHost(host)
+
+	for _, entry := range a {
+		if entry == "*" {
+			return true
+		}
+
+		entryHost, entryPort := entry, uint16(DefaultPort)
+
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			n, err := strconv.ParseUint(p, 10, 16)
+
+			if err != nil {
+				continue
+			}
+
+			entryHost, entryPort = h, uint16(n)
+		}
+
+		if port != entryPort {
+			continue
+		}
+
+		entryHost = normalizeHost(entryHost)
+
+		if entryHost == host {
+			return true
+		}
+
+		if strings.HasPrefix(entryHost, "*.") && strings.HasSuffix(host, entryHost[1:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns host in lower case, without a trailing dot.
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
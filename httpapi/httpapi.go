@@ -0,0 +1,320 @@
+// Package httpapi serves the status of Minecraft servers as JSON over HTTP,
+// for frontends which can't speak the Minecraft protocol.
+//
+// Routes:
+//
+//	GET /status/{host}[:{port}]    Status of the server as JSON
+//	GET /icon/{host}[:{port}].png  Favicon of the server
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+const (
+	DefaultTTL     = time.Minute     // How long results are cached by default
+	DefaultTimeout = 5 * time.Second // Timeout of pings of the default Client
+)
+
+// Status is the JSON response of the status route.
+type Status struct {
+	Host   string    `json:"host"`
+	Port   uint16    `json:"port"`
+	Online bool      `json:"online"`
+	Error  string    `json:"error,omitempty"` // Why the server is offline, without internal details
+	Time   time.Time `json:"time"`            // Time of the ping
+
+	Version *Version `json:"version,omitempty"`
+	Players *Players `json:"players,omitempty"`
+	MOTD    *MOTD    `json:"motd,omitempty"`
+	Latency float64  `json:"latency_ms,omitempty"` // Latency in milliseconds
+	Favicon string   `json:"favicon,omitempty"`    // URL of the favicon, empty when there is none
+}
+
+// Version of a server.
+type Version struct {
+	Name     string `json:"name"`
+	Protocol int32  `json:"protocol"`
+}
+
+// Players of a server.
+type Players struct {
+	Online int32    `json:"online"`
+	Max    int32    `json:"max"`
+	Sample []Player `json:"sample"` // Sampled players, without fake entries
+}
+
+// Player in the player sample.
+type Player struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// MOTD of a server, rendered as text & HTML.
+type MOTD struct {
+	Text string `json:"text"`
+	HTML string `json:"html"`
+}
+
+// Handler serves the status routes, pinging allowed servers.
+type Handler struct {
+	Client    *mcpinger.Client // Client to ping with, a Client with DefaultTimeout when nil
+	Allowlist Allowlist        // Servers which may be pinged
+	TTL       time.Duration    // How long results are cached, DefaultTTL when zero
+	BasePath  string           // Path the handler is mounted at, prefixed to favicon URLs
+
+	once   sync.Once
+	cache  *mcpinger.Cache
+	client *mcpinger.Client // Client, or the default Client
+	now    func() time.Time
+}
+
+// Result of a ping.
+type entry struct {
+	res *mcpinger.Result
+	err error
+	at  time.Time
+}
+
+// NewHandler Creates a new Handler pinging servers on the allowlist with client.
+func NewHandler(client *mcpinger.Client, allowlist Allowlist, ttl time.Duration) *Handler {
+	return &Handler{Client: client, Allowlist: allowlist, TTL: ttl}
+}
+
+// ServeHTTP serves the status & icon routes.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, h.BasePath)
+
+	switch {
+	case strings.HasPrefix(path, "/status/"):
+		h.serveStatus(w, r, strings.TrimPrefix(path, "/status/"))
+	case strings.HasPrefix(path, "/icon/") && strings.HasSuffix(path, ".png"):
+		h.serveIcon(w, r, strings.TrimSuffix(strings.TrimPrefix(path, "/icon/"), ".png"))
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (h *Handler) serveStatus(w http.ResponseWriter, r *http.Request, target string) {
+	host, port, ok := h.target(w, target)
+
+	if !ok {
+		return
+	}
+
+	e := h.query(r.Context(), host, port)
+
+	status := Status{Host: host, Port: port, Time: e.at}
+
+	if e.err != nil {
+		status.Error = publicError(e.err)
+	} else {
+		info := e.res.Info
+
+		status.Online = true
+		status.Version = &Version{Name: info.Version.Name, Protocol: info.Version.Protocol}
+		status.MOTD = &MOTD{Text: info.Description.PlainText(), HTML: info.Description.HTML()}
+		status.Players = &Players{Online: info.Players.Online, Max: info.Players.Max, Sample: []Player{}}
+
+		for _, p := range info.Players.Sample {
+			if !mcpinger.IsFakePlayer(p) {
+				status.Players.Sample = append(status.Players.Sample, Player{Name: p.Name, ID: p.ID})
+			}
+		}
+
+		latency := e.res.Timing.RTT
+
+		if latency == 0 {
+			latency = e.res.Timing.FirstByte
+		}
+
+		status.Latency = float64(latency) / float64(time.Millisecond)
+
+		if info.Favicon != "" {
+			status.Favicon = h.BasePath + "/icon/" + formatTarget(host, port) + ".png"
+		}
+	}
+
+	h.setCacheControl(w, e)
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *Handler) serveIcon(w http.ResponseWriter, r *http.Request, target string) {
+	host, port, ok := h.target(w, target)
+
+	if !ok {
+		return
+	}
+
+	e := h.query(r.Context(), host, port)
+
+	if e.err != nil {
+		writeError(w, http.StatusBadGateway, "server is offline")
+		return
+	}
+
+	icon, err := e.res.Info.FaviconPNG()
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "server has no favicon")
+		return
+	}
+
+	h.setCacheControl(w, e)
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(icon)))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(icon)
+	}
+}
+
+// Parses & checks the target of a request, writing an error response when not ok.
+func (h *Handler) target(w http.ResponseWriter, target string) (string, uint16, bool) {
+	host, port, err := parseTarget(target)
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return "", 0, false
+	}
+
+	if !h.Allowlist.Allowed(host, port) {
+		writeError(w, http.StatusForbidden, "server is not allowed")
+		return "", 0, false
+	}
+
+	return normalizeHost(host), port, true
+}
+
+// Returns the cached result of pinging the server, pinging it when expired.
+// Concurrent requests for the same server share a ping, and failed pings are
+// cached too, so offline servers aren't pinged on every request.
+func (h *Handler) query(ctx context.Context, host string, port uint16) *entry {
+	h.once.Do(func() {
+		h.cache = mcpinger.NewCache(h.ttl())
+		h.cache.Now = h.clock
+		h.client = h.Client
+
+		if h.client == nil {
+			h.client = mcpinger.NewClient(mcpinger.WithTimeout(DefaultTimeout))
+		}
+	})
+
+	res, err := h.cache.Query(ctx, h.client, host, port)
+
+	if err != nil {
+		return &entry{err: err, at: h.clock()}
+	}
+
+	return &entry{res: res, at: res.Time}
+}
+
+func (h *Handler) setCacheControl(w http.ResponseWriter, e *entry) {
+	remaining := h.ttl() - h.clock().Sub(e.at)
+
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(remaining.Seconds())))
+}
+
+func (h *Handler) ttl() time.Duration {
+	if h.TTL <= 0 {
+		return DefaultTTL
+	}
+	return h.TTL
+}
+
+func (h *Handler) clock() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// Parses a target like "example.com", "example.com:25566" or "[::1]:25565".
+func parseTarget(target string) (string, uint16, error) {
+	if target == "" || strings.ContainsAny(target, "/ ") {
+		return "", 0, errors.New("invalid server address")
+	}
+
+	host, portStr, err := net.SplitHostPort(target)
+
+	if err != nil {
+		// No port, which may be a bare IPv6 address
+		host = strings.TrimSuffix(strings.TrimPrefix(target, "["), "]")
+		return host, DefaultPort, nil
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+
+	if err != nil || port == 0 || host == "" {
+		return "", 0, errors.New("invalid server address")
+	}
+
+	return host, uint16(port), nil
+}
+
+// Formats host & port, leaving out the default port.
+func formatTarget(host string, port uint16) string {
+	if port == DefaultPort {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(port)))
+}
+
+// Describes why a ping failed, without the addresses & resolver details of the error.
+func publicError(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var packetErr mcpinger.InvalidPacketError
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return "could not resolve server"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timed out"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.As(err, &packetErr):
+		return "invalid response"
+	default:
+		return "could not ping server"
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{msg})
+}
@@ -0,0 +1,240 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+	"github.com/Raqbit/mc-pinger/internal/mctest"
+)
+
+var icon = []byte("\x89PNG\r\n\x1a\nnot really a png")
+
+var statusJson = `{
+	"version": {"name": "1.21", "protocol": 767},
+	"players": {"max": 20, "online": 2, "sample": [
+		{"name": "Notch", "id": "069a79f4-44e9-4726-a5be-fca90e38aaf5"},
+		{"name": "§6Join now!", "id": "00000000-0000-0000-0000-000000000000"}
+	]},
+	"description": {"text": "Hello ", "extra": [{"text": "world", "color": "gold"}]},
+	"favicon": "data:image/png;base64,` + base64.StdEncoding.EncodeToString(icon) + `"
+}`
+
+// Starts a loopback Minecraft server answering status requests,
+// returning its address & the amount of pings it answered.
+func serveMinecraft(t *testing.T) (string, *int32) {
+	reply := mctest.StatusResponse([]byte(statusJson))
+	pings := new(int32)
+
+	host, port, _ := mctest.Serve(t, func(conn net.Conn, hs mctest.Handshake) {
+		atomic.AddInt32(pings, 1)
+		_, _ = conn.Write(reply)
+	})
+
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), pings
+}
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHandlerStatus(t *testing.T) {
+	addr, pings := serveMinecraft(t)
+
+	h := NewHandler(mcpinger.NewClient(mcpinger.WithTimeout(time.Second)), Allowlist{addr}, time.Minute)
+
+	rec := get(t, h, "/status/"+addr)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d: %s", rec.Code, rec.Body)
+	}
+
+	var status Status
+
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.Online || status.Version.Name != "1.21" || status.Players.Online != 2 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	if len(status.Players.Sample) != 1 || status.Players.Sample[0].Name != "Notch" {
+		t.Errorf("Expected fake players to be filtered, got %+v", status.Players.Sample)
+	}
+
+	if status.MOTD.Text != "Hello world" || status.MOTD.HTML != `Hello <span style="color:#ffaa00">world</span>` {
+		t.Errorf("Unexpected MOTD %+v", status.MOTD)
+	}
+
+	if status.Favicon != "/icon/"+addr+".png" {
+		t.Errorf("Unexpected favicon URL %s", status.Favicon)
+	}
+
+	// Served from the cache
+	get(t, h, "/status/"+addr)
+
+	rec = get(t, h, status.Favicon)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" || !bytes.Equal(rec.Body.Bytes(), icon) {
+		t.Errorf("Unexpected icon response %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	if n := atomic.LoadInt32(pings); n != 1 {
+		t.Errorf("Expected a single ping within the TTL, got %d", n)
+	}
+}
+
+func TestHandlerExpired(t *testing.T) {
+	addr, pings := serveMinecraft(t)
+
+	now := time.Now()
+
+	h := NewHandler(mcpinger.NewClient(mcpinger.WithTimeout(time.Second)), Allowlist{addr}, time.Minute)
+	h.now = func() time.Time { return now }
+
+	get(t, h, "/status/"+addr)
+
+	now = now.Add(2 * time.Minute)
+
+	get(t, h, "/status/"+addr)
+
+	if n := atomic.LoadInt32(pings); n != 2 {
+		t.Errorf("Expected the expired result to be pinged again, got %d pings", n)
+	}
+}
+
+func TestHandlerOffline(t *testing.T) {
+	// Nothing listens on the port of a closed listener
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().String()
+	l.Close()
+
+	h := NewHandler(mcpinger.NewClient(mcpinger.WithTimeout(time.Second)), Allowlist{addr}, time.Minute)
+
+	rec := get(t, h, "/status/"+addr)
+
+	var status Status
+	_ = json.Unmarshal(rec.Body.Bytes(), &status)
+
+	if rec.Code != http.StatusOK || status.Online || status.Error != "connection refused" {
+		t.Errorf("Expected an offline status, got %d %s", rec.Code, rec.Body)
+	}
+
+	if strings.Contains(rec.Body.String(), "127.0.0.1:") {
+		t.Errorf("Expected the error not to contain the address, got %s", rec.Body)
+	}
+
+	if rec = get(t, h, "/icon/"+addr+".png"); rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected a bad gateway for the icon of an offline server, got %d", rec.Code)
+	}
+}
+
+func TestHandlerRoutes(t *testing.T) {
+	h := NewHandler(nil, Allowlist{"mc.example.com"}, time.Minute)
+
+	tests := []struct {
+		Method string
+		Path   string
+		Status int
+	}{
+		{http.MethodGet, "/status/evil.example.com", http.StatusForbidden},
+		{http.MethodGet, "/status/mc.example.com:25566", http.StatusForbidden},
+		{http.MethodGet, "/status/mc.example.com:0", http.StatusBadRequest},
+		{http.MethodGet, "/status/", http.StatusBadRequest},
+		{http.MethodGet, "/icon/evil.example.com.png", http.StatusForbidden},
+		{http.MethodGet, "/nothing", http.StatusNotFound},
+		{http.MethodPost, "/status/mc.example.com", http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(test.Method, test.Path, nil))
+
+		if rec.Code != test.Status {
+			t.Errorf("%s %s: expected status %d, got %d", test.Method, test.Path, test.Status, rec.Code)
+		}
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	allowlist := Allowlist{"mc.example.com", "*.example.net", "10.0.0.1:25566", "::1"}
+
+	tests := []struct {
+		Host     string
+		Port     uint16
+		Expected bool
+	}{
+		{"mc.example.com", 25565, true},
+		{"MC.Example.com.", 25565, true},
+		{"mc.example.com", 25566, false},
+		{"play.example.net", 25565, true},
+		{"example.net", 25565, false},
+		{"10.0.0.1", 25566, true},
+		{"10.0.0.1", 25565, false},
+		{"::1", 25565, true},
+		{"mc.example.org", 25565, false},
+	}
+
+	for _, test := range tests {
+		if actual := allowlist.Allowed(test.Host, test.Port); actual != test.Expected {
+			t.Errorf("Allowed(%s, %d) = %t, expected %t", test.Host, test.Port, actual, test.Expected)
+		}
+	}
+
+	if !(Allowlist{"*"}).Allowed("anything", 1) {
+		t.Error("Expected * to allow all servers")
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		Target string
+		Host   string
+		Port   uint16
+	}{
+		{"mc.example.com", "mc.example.com", 25565},
+		{"mc.example.com:25566", "mc.example.com", 25566},
+		{"[::1]:25566", "::1", 25566},
+		{"[::1]", "::1", 25565},
+		{"::1", "::1", 25565},
+	}
+
+	for _, test := range tests {
+		host, port, err := parseTarget(test.Target)
+
+		if err != nil || host != test.Host || port != test.Port {
+			t.Errorf("Unable to parse %s, got %s %d %v", test.Target, host, port, err)
+		}
+	}
+}
+
+func TestFormatTarget(t *testing.T) {
+	tests := []struct {
+		Host     string
+		Port     uint16
+		Expected string
+	}{
+		{"mc.example.com", 25565, "mc.example.com"},
+		{"mc.example.com", 25566, "mc.example.com:25566"},
+		{"::1", 25565, "[::1]"},
+		{"::1", 25566, "[::1]:25566"},
+	}
+
+	for _, test := range tests {
+		if actual := formatTarget(test.Host, test.Port); actual != test.Expected {
+			t.Errorf("Unable to format %s %d, expected %s, got %s", test.Host, test.Port, test.Expected, actual)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package mctest provides loopback Minecraft servers for tests.
+package mctest
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	enc "github.com/Raqbit/mc-pinger/encoding"
+)
+
+// Handshake is the handshake a client sent to a test server.
+type Handshake struct {
+	ProtoVer  int32
+	Address   string // Server address, including any FML marker or forwarded data
+	Port      uint16
+	NextState int32
+}
+
+// Serve starts a loopback server calling handle for every connection, concurrently,
+// after reading its handshake & the packet following it. Connections are closed
+// when handle returns. The amount of accepted connections is returned.
+func Serve(t testing.TB, handle func(conn net.Conn, hs Handshake)) (string, uint16, *int32) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	conns := new(int32)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+
+			if err != nil {
+				return
+			}
+
+			atomic.AddInt32(conns, 1)
+
+			go func() {
+				defer conn.Close()
+
+				hs, err := readHandshake(conn)
+
+				if err != nil {
+					return
+				}
+
+				// Skip the status request or Login Start
+				if _, err = ReadPacket(conn); err != nil {
+					return
+				}
+
+				handle(conn, hs)
+			}()
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), uint16(addr.Port), conns
+}
+
+// ReadPacket reads the body of an uncompressed packet, including its ID.
+func ReadPacket(r io.Reader) ([]byte, error) {
+	length, err := enc.ReadVarInt(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+
+	return body, err
+}
+
+func readHandshake(r io.Reader) (Handshake, error) {
+	body, err := ReadPacket(r)
+
+	if err != nil {
+		return Handshake{}, err
+	}
+
+	rd := bytes.NewReader(body)
+
+	// Skip packet ID
+	_, _ = enc.ReadVarInt(rd)
+
+	protoVer, _ := enc.ReadVarInt(rd)
+	address, _ := enc.ReadString(rd)
+	port, _ := enc.ReadUnsignedShort(rd)
+	nextState, err := enc.ReadVarInt(rd)
+
+	return Handshake{
+		ProtoVer:  int32(protoVer),
+		Address:   string(address),
+		Port:      uint16(port),
+		NextState: int32(nextState),
+	}, err
+}
+
+// Packet builds a raw uncompressed packet with the given ID and body.
+func Packet(id enc.VarInt, body func(w io.Writer)) []byte {
+	var data bytes.Buffer
+	_ = enc.WriteVarInt(&data, id)
+	body(&data)
+
+	var pkt bytes.Buffer
+	_ = enc.WriteVarInt(&pkt, enc.VarInt(data.Len()))
+	pkt.Write(data.Bytes())
+
+	return pkt.Bytes()
+}
+
+// StatusResponse builds a status response packet with the given JSON.
+func StatusResponse(json []byte) []byte {
+	return Packet(0x00, func(w io.Writer) {
+		_ = enc.WriteString(w, enc.String(json))
+	})
+}
+
+// EchoPings answers ping packets read from conn with the same packet, until it is closed.
+func EchoPings(conn net.Conn) {
+	for {
+		ping, err := ReadPacket(conn)
+
+		if err != nil {
+			return
+		}
+
+		_ = enc.WriteVarInt(conn, enc.VarInt(len(ping)))
+		_, _ = conn.Write(ping)
+	}
+}
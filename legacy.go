@@ -0,0 +1,203 @@
+package mcpinger
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	enc "github.com/Raqbit/mc-pinger/encoding"
+)
+
+const (
+	// Protocol version sent in the legacy ping, that of 1.6.4
+	LegacyProtoVersion = 74
+
+	// Maximum length of a legacy ping response, in UTF-16 code units
+	legacyMaxLength = 1 << 15
+)
+
+// LegacyInfo is the server info of a legacy ping, answered by servers before 1.7
+// and by most newer servers for compatibility.
+// See: https://wiki.vg/Server_List_Ping#1.6
+type LegacyInfo struct {
+	Protocol int32  // Protocol version, -1 for servers before 1.4
+	Version  string // Version name, empty for servers before 1.4
+	MOTD     string // Description, possibly containing legacy § formatting codes
+	Online   int32  // Amount of players online
+	Max      int32  // Max amount of players allowed
+}
+
+// PingLegacy pings the Minecraft server using the legacy 0xFE server list ping,
+// used by clients before 1.7.
+func (c *Client) PingLegacy(ctx context.Context, host string, port uint16) (*LegacyInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	trace := ContextPingTrace(ctx)
+
+	conn, err := c.connect(ctx, host, port, nil, new(Timing))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	defer watchContext(ctx, conn)()
+
+	w := bufio.NewWriter(conn)
+
+	err = writeLegacyPing(w, host, port)
+
+	if err == nil {
+		_ = conn.SetWriteDeadline(phaseDeadline(ctx, c.WriteTimeout))
+		err = w.Flush()
+	}
+
+	trace.handshakeSent(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetReadDeadline(phaseDeadline(ctx, c.ReadTimeout))
+
+	return readLegacyResponse(bufio.NewReader(conn))
+}
+
+// Writes the 1.6 legacy ping, which servers before 1.6 answer too.
+func writeLegacyPing(w io.Writer, host string, port uint16) error {
+	hostChars := utf16.Encode([]rune(host))
+
+	// Packet ID, server list ping payload & plugin message ID
+	b := []byte{0xFE, 0x01, 0xFA}
+	b = appendLegacyString(b, "MC|PingHost")
+
+	// Length of the rest of the plugin message
+	b = appendUint16(b, uint16(7+2*len(hostChars)))
+
+	b = append(b, LegacyProtoVersion)
+	b = appendLegacyString(b, host)
+	b = append(b, 0, 0, byte(port>>8), byte(port))
+
+	_, err := w.Write(b)
+
+	return err
+}
+
+// Reads the kick packet answering a legacy ping.
+func readLegacyResponse(rd io.Reader) (*LegacyInfo, error) {
+	var header [3]byte
+
+	if _, err := io.ReadFull(rd, header[:]); err != nil {
+		return nil, err
+	}
+
+	if header[0] != 0xFF {
+		return nil, InvalidPacketError{expected: 0xFF, actual: enc.VarInt(header[0])}
+	}
+
+	length := binary.BigEndian.Uint16(header[1:])
+
+	if length > legacyMaxLength {
+		return nil, errors.New("legacy response too long")
+	}
+
+	data := make([]byte, 2*int(length))
+
+	if _, err := io.ReadFull(rd, data); err != nil {
+		return nil, err
+	}
+
+	chars := make([]uint16, length)
+	for i := range chars {
+		chars[i] = binary.BigEndian.Uint16(data[2*i:])
+	}
+
+	return parseLegacyResponse(string(utf16.Decode(chars)))
+}
+
+// Parses the reason of a legacy kick packet.
+func parseLegacyResponse(s string) (*LegacyInfo, error) {
+	// 1.4 and later: §1\0protocol\0version\0motd\0online\0max
+	if strings.HasPrefix(s, "§1\x00") {
+		fields := strings.Split(s, "\x00")
+
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("could not parse legacy response: expected 6 fields, got %d", len(fields))
+		}
+
+		protocol, err := strconv.ParseInt(fields[1], 10, 32)
+
+		if err != nil {
+			return nil, errors.New("could not parse legacy protocol: " + err.Error())
+		}
+
+		info := &LegacyInfo{Protocol: int32(protocol), Version: fields[2], MOTD: fields[3]}
+
+		if info.Online, info.Max, err = parseLegacyPlayers(fields[4], fields[5]); err != nil {
+			return nil, err
+		}
+
+		return info, nil
+	}
+
+	// Before 1.4: motd§online§max, where the MOTD may contain § itself
+	fields := strings.Split(s, "§")
+
+	if len(fields) < 3 {
+		return nil, errors.New("could not parse legacy response")
+	}
+
+	info := &LegacyInfo{Protocol: UnknownProtoVersion, MOTD: strings.Join(fields[:len(fields)-2], "§")}
+
+	var err error
+
+	if info.Online, info.Max, err = parseLegacyPlayers(fields[len(fields)-2], fields[len(fields)-1]); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func parseLegacyPlayers(online, max string) (int32, int32, error) {
+	o, err := strconv.ParseInt(online, 10, 32)
+
+	if err != nil {
+		return 0, 0, errors.New("could not parse legacy player count: " + err.Error())
+	}
+
+	m, err := strconv.ParseInt(max, 10, 32)
+
+	if err != nil {
+		return 0, 0, errors.New("could not parse legacy max players: " + err.Error())
+	}
+
+	return int32(o), int32(m), nil
+}
+
+// Appends a string prefixed by its length, encoded as UTF-16BE.
+func appendLegacyString(b []byte, s string) []byte {
+	chars := utf16.Encode([]rune(s))
+
+	b = appendUint16(b, uint16(len(chars)))
+
+	for _, c := range chars {
+		b = appendUint16(b, c)
+	}
+
+	return b
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
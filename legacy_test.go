@@ -0,0 +1,132 @@
+package mcpinger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+func TestParseLegacyResponse(t *testing.T) {
+	tests := []struct {
+		Response string
+		Expected LegacyInfo
+	}{
+		{
+			Response: "§1\x0074\x001.6.4\x00A Minecraft Server\x003\x0020",
+			Expected: LegacyInfo{Protocol: 74, Version: "1.6.4", MOTD: "A Minecraft Server", Online: 3, Max: 20},
+		},
+		{
+			Response: "A §aMinecraft Server§3§20",
+			Expected: LegacyInfo{Protocol: -1, MOTD: "A §aMinecraft Server", Online: 3, Max: 20},
+		},
+	}
+
+	for _, test := range tests {
+		info, err := parseLegacyResponse(test.Response)
+
+		if err != nil {
+			t.Errorf("Unable to parse %q: %v", test.Response, err)
+			continue
+		}
+
+		if *info != test.Expected {
+			t.Errorf("Unable to parse %q, expected %+v, got %+v", test.Response, test.Expected, *info)
+		}
+	}
+
+	for _, invalid := range []string{"", "§1\x0074\x001.6.4", "motd§many§players"} {
+		if _, err := parseLegacyResponse(invalid); err == nil {
+			t.Errorf("Expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestWriteLegacyPing(t *testing.T) {
+	var b bytes.Buffer
+
+	if err := writeLegacyPing(&b, "localhost", 25565); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{
+		0xFE, 0x01, 0xFA,
+		0x00, 0x0B, // Length of MC|PingHost
+		0x00, 'M', 0x00, 'C', 0x00, '|', 0x00, 'P', 0x00, 'i', 0x00, 'n', 0x00, 'g', 0x00, 'H', 0x00, 'o', 0x00, 's', 0x00, 't',
+		0x00, 0x19, // 7 + 2 * len(localhost)
+		74,
+		0x00, 0x09,
+		0x00, 'l', 0x00, 'o', 0x00, 'c', 0x00, 'a', 0x00, 'l', 0x00, 'h', 0x00, 'o', 0x00, 's', 0x00, 't',
+		0x00, 0x00, 0x63, 0xDD,
+	}
+
+	if !bytes.Equal(b.Bytes(), expected) {
+		t.Errorf("Unexpected legacy ping\nexpected % x\ngot      % x", expected, b.Bytes())
+	}
+}
+
+func TestClientPingLegacy(t *testing.T) {
+	host, port := serveLegacy(t, "§1\x0074\x001.6.4\x00A Minecraft Server\x003\x0020")
+
+	info, err := NewClient(WithTimeout(time.Second)).PingLegacy(context.Background(), host, port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Version != "1.6.4" || info.Online != 3 {
+		t.Errorf("Unexpected legacy info %+v", info)
+	}
+}
+
+// Starts a loopback server answering a legacy ping with the given response.
+func serveLegacy(t *testing.T, response string) (string, uint16) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		// Read the ping up to the port
+		var ping bytes.Buffer
+		_ = writeLegacyPing(&ping, "127.0.0.1", 0)
+
+		if _, err = io.CopyN(io.Discard, conn, int64(ping.Len())); err != nil {
+			return
+		}
+
+		_, _ = conn.Write(legacyResponse(response))
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), uint16(addr.Port)
+}
+
+// Returns the kick packet of a legacy response.
+func legacyResponse(response string) []byte {
+	chars := utf16.Encode([]rune(response))
+
+	b := []byte{0xFF}
+	b = appendUint16(b, uint16(len(chars)))
+
+	for _, c := range chars {
+		b = appendUint16(b, c)
+	}
+
+	return b
+}
@@ -0,0 +1,319 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultRefusalBackoff    = time.Second // Initial backoff after a refused connection
+	DefaultMaxRefusalBackoff = time.Minute // Maximum backoff after refused connections
+)
+
+// Rate is a rate of connections, allowing a connection every interval
+// with bursts of up to Burst connections. A zero Rate is unlimited.
+type Rate struct {
+	Every time.Duration
+	Burst int
+}
+
+// Limiter limits the connections made by clients, so mass pinging doesn't trip the
+// anti-DDoS of shared hosting ranges. It limits the rate of connections per destination IP,
+// per subnet (/24 for IPv4, /64 for IPv6) and globally, caps the concurrent connections
+// per destination IP, and backs off from subnets refusing connections.
+// A Limiter may be shared by many clients, and is safe for concurrent use.
+type Limiter struct {
+	PerIP      Rate // Connection rate per destination IP
+	PerSubnet  Rate // Connection rate per destination subnet
+	Global     Rate // Connection rate over all destinations
+	MaxPerHost int  // Concurrent connections per destination IP, unlimited when zero
+
+	// Backoff from a subnet after a refused connection, doubling with every
+	// consecutive refusal up to MaxRefusalBackoff.
+	// Defaults to DefaultRefusalBackoff & DefaultMaxRefusalBackoff when zero.
+	RefusalBackoff    time.Duration
+	MaxRefusalBackoff time.Duration
+
+	mu       sync.Mutex
+	hosts    map[string]*hostLimit
+	subnets  map[string]*subnetLimit
+	global   bucket
+	acquires int
+	now      func() time.Time
+}
+
+type hostLimit struct {
+	bucket bucket
+	sem    chan struct{} // Concurrent connections, nil when unlimited
+	users  int           // Waits holding the limit, which keep it from being swept
+}
+
+type subnetLimit struct {
+	bucket   bucket
+	users    int       // Waits holding the limit, which keep it from being swept
+	refusals int       // Consecutive refused connections
+	until    time.Time // Backing off until
+}
+
+// Token bucket, full when unused.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter Creates a new Limiter with the given rates.
+func NewLimiter(perIP, perSubnet, global Rate) *Limiter {
+	return &Limiter{PerIP: perIP, PerSubnet: perSubnet, Global: global}
+}
+
+// WithLimiter limits the connections made through the given limiter.
+func WithLimiter(limiter *Limiter) McPingerOption {
+	return func(p *mcPinger) {
+		p.Limiter = limiter
+	}
+}
+
+// Wait waits until a connection to ip is allowed, returning a function
+// which must be called when the connection is closed. Clients with the
+// Limiter call it themselves, it is only needed for other connections.
+func (l *Limiter) Wait(ctx context.Context, ip net.IP) (func(), error) {
+	host, subnet := l.limits(ip, true)
+
+	done := func() {
+		l.mu.Lock()
+		host.users--
+		subnet.users--
+		l.mu.Unlock()
+	}
+
+	if host.sem != nil {
+		select {
+		case host.sem <- struct{}{}:
+		case <-ctx.Done():
+			done()
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if host.sem != nil {
+			<-host.sem
+		}
+
+		done()
+	}
+
+	for {
+		l.mu.Lock()
+
+		now := l.clock()
+		wait := subnet.until.Sub(now)
+
+		for _, w := range []time.Duration{
+			host.bucket.wait(l.PerIP, now),
+			subnet.bucket.wait(l.PerSubnet, now),
+			l.global.wait(l.Global, now),
+		} {
+			if w > wait {
+				wait = w
+			}
+		}
+
+		if wait <= 0 {
+			host.bucket.take(l.PerIP)
+			subnet.bucket.take(l.PerSubnet)
+			l.global.take(l.Global)
+			l.mu.Unlock()
+			break
+		}
+
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+// Returns the limits of ip & its subnet, creating them when missing.
+// With hold, the limits are kept until the user count is decremented again.
+func (l *Limiter) limits(ip net.IP, hold bool) (*hostLimit, *subnetLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.hosts == nil {
+		l.hosts = make(map[string]*hostLimit)
+		l.subnets = make(map[string]*subnetLimit)
+	}
+
+	l.acquires++
+
+	// Forget idle limits now and then, so scanning large ranges doesn't keep every IP around
+	if l.acquires%1024 == 0 {
+		l.sweep()
+	}
+
+	host, ok := l.hosts[ip.String()]
+
+	if !ok {
+		host = new(hostLimit)
+
+		if l.MaxPerHost > 0 {
+			host.sem = make(chan struct{}, l.MaxPerHost)
+		}
+
+		l.hosts[ip.String()] = host
+	}
+
+	key := subnetKey(ip)
+	subnet, ok := l.subnets[key]
+
+	if !ok {
+		subnet = new(subnetLimit)
+		l.subnets[key] = subnet
+	}
+
+	if hold {
+		host.users++
+		subnet.users++
+	}
+
+	return host, subnet
+}
+
+// Report records the result of connecting to ip, backing off from its subnet when refused.
+func (l *Limiter) Report(ip net.IP, err error) {
+	_, subnet := l.limits(ip, false)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err == nil {
+		subnet.refusals = 0
+		return
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return
+	}
+
+	backoff, max := l.RefusalBackoff, l.MaxRefusalBackoff
+
+	if backoff <= 0 {
+		backoff = DefaultRefusalBackoff
+	}
+
+	if max <= 0 {
+		max = DefaultMaxRefusalBackoff
+	}
+
+	for i := 0; i < subnet.refusals && backoff < max; i++ {
+		backoff *= 2
+	}
+
+	if backoff > max {
+		backoff = max
+	}
+
+	subnet.refusals++
+	subnet.until = l.clock().Add(backoff)
+}
+
+// Removes limits which are back to their initial state, l.mu must be held.
+func (l *Limiter) sweep() {
+	now := l.clock()
+
+	for key, host := range l.hosts {
+		if host.users == 0 && host.bucket.full(l.PerIP, now) {
+			delete(l.hosts, key)
+		}
+	}
+
+	for key, subnet := range l.subnets {
+		if subnet.users == 0 && subnet.refusals == 0 && subnet.bucket.full(l.PerSubnet, now) {
+			delete(l.subnets, key)
+		}
+	}
+}
+
+func (l *Limiter) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
+
+// Returns how long until a token is available, refilling the bucket.
+func (b *bucket) wait(rate Rate, now time.Time) time.Duration {
+	if rate.Every <= 0 {
+		return 0
+	}
+
+	burst := float64(rate.Burst)
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens += float64(now.Sub(b.last)) / float64(rate.Every)
+
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+
+	b.last = now
+
+	if b.tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) * float64(rate.Every))
+}
+
+func (b *bucket) take(rate Rate) {
+	if rate.Every > 0 {
+		b.tokens--
+	}
+}
+
+// Reports whether the bucket has refilled, so it can be forgotten.
+func (b *bucket) full(rate Rate, now time.Time) bool {
+	return rate.Every <= 0 || b.last.IsZero() || now.Sub(b.last) >= time.Duration(rate.Burst+1)*rate.Every
+}
+
+// Returns the subnet of ip, /24 for IPv4 & /64 for IPv6.
+func subnetKey(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String()
+	}
+
+	return ip.Mask(net.CIDRMask(64, 128)).String()
+}
+
+// Connection releasing its slot of the Limiter when closed.
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
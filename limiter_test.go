@@ -0,0 +1,194 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestLimiterRate(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Limiter *Limiter
+		IPs     []string
+	}{
+		{"per IP", &Limiter{PerIP: Rate{Every: 50 * time.Millisecond}}, []string{"127.0.0.1", "127.0.0.1", "127.0.0.1"}},
+		{"per subnet", &Limiter{PerSubnet: Rate{Every: 50 * time.Millisecond}}, []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"}},
+		{"global", &Limiter{Global: Rate{Every: 50 * time.Millisecond}}, []string{"127.0.0.1", "10.0.0.1", "::1"}},
+	}
+
+	for _, test := range tests {
+		start := time.Now()
+
+		for _, ip := range test.IPs {
+			release, err := test.Limiter.Wait(context.Background(), net.ParseIP(ip))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			release()
+		}
+
+		// The first connection is allowed right away
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Errorf("%s: expected 3 connections to take at least 100ms, took %s", test.Name, elapsed)
+		}
+	}
+}
+
+func TestLimiterUnrelated(t *testing.T) {
+	l := &Limiter{PerIP: Rate{Every: time.Hour}, PerSubnet: Rate{Every: time.Hour}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for _, ip := range []string{"127.0.0.1", "127.0.1.1", "10.0.0.1"} {
+		if _, err := l.Wait(ctx, net.ParseIP(ip)); err != nil {
+			t.Errorf("Expected %s in another subnet to be allowed, got %v", ip, err)
+		}
+	}
+}
+
+func TestLimiterMaxPerHost(t *testing.T) {
+	l := &Limiter{MaxPerHost: 1}
+	ip := net.ParseIP("127.0.0.1")
+
+	release, err := l.Wait(context.Background(), ip)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err = l.Wait(ctx, ip); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a second connection to wait, got %v", err)
+	}
+
+	release()
+
+	if _, err = l.Wait(context.Background(), ip); err != nil {
+		t.Errorf("Expected a connection after releasing, got %v", err)
+	}
+}
+
+func TestLimiterSweepWaiting(t *testing.T) {
+	l := &Limiter{MaxPerHost: 1}
+	ip := net.ParseIP("127.0.0.1")
+
+	// A Wait which looked up the limits, but didn't take a connection yet
+	host, _ := l.limits(ip, true)
+
+	l.mu.Lock()
+	l.sweep()
+	l.mu.Unlock()
+
+	if other, _ := l.limits(ip, false); other != host {
+		t.Fatal("Expected limits held by a Wait not to be swept")
+	}
+
+	release, err := l.Wait(context.Background(), ip)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release()
+
+	l.mu.Lock()
+	l.sweep()
+	_, ok := l.hosts[ip.String()]
+	l.mu.Unlock()
+
+	// Still held by the first lookup
+	if !ok {
+		t.Error("Expected the held limits to stay after releasing another Wait")
+	}
+}
+
+func TestLimiterRefusalBackoff(t *testing.T) {
+	// Nothing listens on the port of a closed listener
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := l.Addr().(*net.TCPAddr)
+	l.Close()
+
+	limiter := &Limiter{RefusalBackoff: 100 * time.Millisecond, MaxRefusalBackoff: 150 * time.Millisecond}
+	client := NewClient(WithLimiter(limiter))
+
+	if _, err := client.Ping(context.Background(), "127.0.0.1", uint16(addr.Port)); err == nil {
+		t.Fatal("Expected the connection to be refused")
+	}
+
+	start := time.Now()
+
+	_, _ = client.Ping(context.Background(), "127.0.0.2", uint16(addr.Port))
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected to back off from the subnet after a refusal, took %s", elapsed)
+	}
+
+	// Consecutive refusals double the backoff, up to the maximum
+	_, subnet := limiter.limits(addr.IP, false)
+
+	limiter.mu.Lock()
+	refusals, backoff := subnet.refusals, time.Until(subnet.until)
+	limiter.mu.Unlock()
+
+	if refusals != 2 || backoff <= 100*time.Millisecond || backoff > 150*time.Millisecond {
+		t.Errorf("Expected a capped doubled backoff after 2 refusals, got %d refusals and %s", refusals, backoff)
+	}
+}
+
+func TestSubnetKey(t *testing.T) {
+	tests := []struct {
+		IP       string
+		Expected string
+	}{
+		{"192.168.1.42", "192.168.1.0"},
+		{"::ffff:192.168.1.42", "192.168.1.0"},
+		{"2001:db8:1:2:3:4:5:6", "2001:db8:1:2::"},
+	}
+
+	for _, test := range tests {
+		if actual := subnetKey(net.ParseIP(test.IP)); actual != test.Expected {
+			t.Errorf("Unable to get subnet of %s, expected %s, got %s", test.IP, test.Expected, actual)
+		}
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	host, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	targets := make([]Target, 6)
+	for i := range targets {
+		targets[i] = Target{Host: host, Port: port}
+	}
+
+	limiter := &Limiter{MaxPerHost: 1}
+	client := NewClient(WithTimeout(time.Second), WithLimiter(limiter))
+
+	count := 0
+
+	for res := range client.QueryAll(context.Background(), targets, 3) {
+		if res.Err != nil {
+			t.Error(res.Err)
+		} else if res.Result.Info.Version.Name != "1.13.2" {
+			t.Error("Did not parse version name correctly")
+		}
+
+		count++
+	}
+
+	if count != len(targets) {
+		t.Errorf("Expected %d results, got %d", len(targets), count)
+	}
+
+	// All connections were released
+	if host, _ := limiter.limits(net.ParseIP(host), false); len(host.sem) != 0 {
+		t.Errorf("Expected all connections to be released, %d are held", len(host.sem))
+	}
+}
@@ -0,0 +1,317 @@
+package mcpinger
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"github.com/Raqbit/mc-pinger/packet"
+)
+
+const (
+	// DefaultLoginProtoVersion is the protocol version (1.21) sent when probing the
+	// login state without an explicit protocol version of a server which did not
+	// report its own, as servers refuse logins for UnknownProtoVersion.
+	DefaultLoginProtoVersion = 767
+
+	// DefaultUsername is the player name sent in Login Start when none was configured.
+	DefaultUsername = "mcpinger"
+)
+
+// LoginProber allows you to probe the login state of a server.
+type LoginProber interface {
+	ProbeLogin() (*LoginResult, error)
+}
+
+// LoginOutcome is the kind of packet the server answered Login Start with.
+type LoginOutcome int
+
+const (
+	LoginEncryptionRequest LoginOutcome = iota + 1 // Server is in online mode
+	LoginSetCompression                            // Server is in offline mode and enabled compression
+	LoginSuccess                                   // Server is in offline mode
+	LoginDisconnect                                // Server refused the login
+	LoginPluginRequest                             // Server started a custom login exchange
+)
+
+func (o LoginOutcome) String() string {
+	switch o {
+	case LoginEncryptionRequest:
+		return "encryption request"
+	case LoginSetCompression:
+		return "set compression"
+	case LoginSuccess:
+		return "login success"
+	case LoginDisconnect:
+		return "disconnect"
+	case LoginPluginRequest:
+		return "plugin request"
+	default:
+		return fmt.Sprintf("LoginOutcome(%d)", int(o))
+	}
+}
+
+// LoginResult describes how a server answered a login attempt.
+// Only the fields belonging to the Outcome are set.
+type LoginResult struct {
+	Outcome LoginOutcome
+
+	// Encryption Request
+	ServerID           string // Server ID, empty on modern servers
+	PublicKey          []byte // DER encoded RSA public key of the server
+	VerifyToken        []byte // Token the client has to encrypt and send back
+	ShouldAuthenticate bool   // Whether the server authenticates with Mojang
+
+	// Set Compression
+	CompressionThreshold int32 // Minimum size of a packet before it is compressed
+
+	// Login Success
+	UUID     string // UUID the server assigned to the player
+	Username string // Name the server assigned to the player
+
+	// Disconnect
+	Reason *ChatComponent // Reason the login was refused
+
+	// Login Plugin Request
+	PluginChannel string // Channel of the custom login exchange
+	PluginData    []byte // Data sent on the channel
+}
+
+// OnlineMode returns whether the server requested encryption,
+// which only servers in online mode do.
+func (r *LoginResult) OnlineMode() bool {
+	return r.Outcome == LoginEncryptionRequest
+}
+
+// UnexpectedPacketError returned when the server answers the
+// login with an unknown packet.
+type UnexpectedPacketError struct {
+	ID enc.VarInt
+}
+
+func (u UnexpectedPacketError) Error() string {
+	return fmt.Sprintf("Received unexpected packet #%d", u.ID)
+}
+
+func (p *mcPinger) ProbeLogin() (*LoginResult, error) {
+	return p.Client.ProbeLogin(p.Context, p.Host, p.Port)
+}
+
+// ProbeLogin connects to the Minecraft server, starts a login and classifies
+// the first reply. The connection is closed before joining the world.
+// Without a protocol version, the server is pinged first to log in with the
+// version it reports, as servers refuse logins of other versions.
+func (c *Client) ProbeLogin(ctx context.Context, host string, port uint16) (*LoginResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	protoVer := c.loginProtoVersion(ctx, host, port)
+
+	return c.probeLogin(ctx, host, port, protoVer, c.handshakeAddress(host, protoVer), false)
+}
+
+// Returns the protocol version sent when probing the login state: the ProtoVersion,
+// or the version the server reports, or DefaultLoginProtoVersion when it reports none.
+func (c *Client) loginProtoVersion(ctx context.Context, host string, port uint16) int32 {
+	if c.ProtoVersion != UnknownProtoVersion {
+		return c.ProtoVersion
+	}
+
+	if res, err := c.Query(ctx, host, port); err == nil && res.Info.Version.Protocol > 0 {
+		return res.Info.Version.Protocol
+	}
+
+	return DefaultLoginProtoVersion
+}
+
+// Probes the login state with protoVer, sending address as the server address of the handshake.
+// With pastCompression, the reply following Set Compression is returned instead.
+func (c *Client) probeLogin(ctx context.Context, host string, port uint16, protoVer int32, address string, pastCompression bool) (*LoginResult, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	trace := ContextPingTrace(ctx)
+
+	conn, err := c.connect(ctx, host, port, nil, new(Timing))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	defer watchContext(ctx, conn)()
+
+	rd := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	err = sendHandshakePacket(w, address, port, protoVer, LoginState)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.sendLoginStartPacket(w, protoVer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetWriteDeadline(phaseDeadline(ctx, c.WriteTimeout))
+	err = w.Flush()
+	trace.handshakeSent(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_ = conn.SetReadDeadline(phaseDeadline(ctx, c.ReadTimeout))
+
+	res, err := readLoginReply(rd, protoVer, trace)
+
+	if err != nil || !pastCompression || res.Outcome != LoginSetCompression {
+		return res, err
+	}
+
+	// Packets following Set Compression are in the compressed format
+	return readLoginReply(bufio.NewReader(packet.NewCompressedReader(rd)), protoVer, nil)
+}
+
+func (c *Client) sendLoginStartPacket(w *bufio.Writer, protoVer int32) error {
+	name := c.Username
+
+	if name == "" {
+		name = DefaultUsername
+	}
+
+	uuid := offlineUUID(name)
+
+	if c.UUID != "" {
+		var err error
+		if uuid, err = enc.ParseUUID(c.UUID); err != nil {
+			return errors.New("invalid UUID: " + err.Error())
+		}
+	}
+
+	loginStartPkt := &packet.LoginStartPacket{
+		Name:     enc.String(name),
+		UUID:     uuid,
+		ProtoVer: enc.VarInt(protoVer),
+	}
+
+	err := packet.WritePacket(loginStartPkt, w)
+
+	if err != nil {
+		return errors.New("could not pack: " + err.Error())
+	}
+
+	return nil
+}
+
+// Reads the first login packet and classifies it.
+func readLoginReply(rd *bufio.Reader, protoVer int32, trace *PingTrace) (*LoginResult, error) {
+	pLen, packetID, err := packet.ReadPacketHeader(rd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	trace.responseHeaderRead(int32(pLen), int32(packetID))
+
+	// Limit the body so packets extending to their end can be read
+	body := io.LimitReader(rd, int64(pLen)-int64(enc.VarIntSize(packetID)))
+
+	switch packetID {
+	case packet.DisconnectPacket{}.ID():
+		pkt := &packet.DisconnectPacket{}
+		if err = pkt.Unmarshal(body); err != nil {
+			return nil, err
+		}
+
+		reason := new(ChatComponent)
+		if err = reason.UnmarshalJSON([]byte(pkt.Reason)); err != nil {
+			return nil, err
+		}
+
+		return &LoginResult{Outcome: LoginDisconnect, Reason: reason}, nil
+	case packet.EncryptionRequestPacket{}.ID():
+		pkt := &packet.EncryptionRequestPacket{ProtoVer: enc.VarInt(protoVer)}
+		if err = pkt.Unmarshal(body); err != nil {
+			return nil, err
+		}
+
+		return &LoginResult{
+			Outcome:            LoginEncryptionRequest,
+			ServerID:           string(pkt.ServerID),
+			PublicKey:          pkt.PublicKey,
+			VerifyToken:        pkt.VerifyToken,
+			ShouldAuthenticate: bool(pkt.ShouldAuthenticate),
+		}, nil
+	case packet.LoginSuccessPacket{}.ID():
+		pkt := &packet.LoginSuccessPacket{ProtoVer: enc.VarInt(protoVer)}
+		if err = pkt.Unmarshal(body); err != nil {
+			return nil, err
+		}
+
+		return &LoginResult{
+			Outcome:  LoginSuccess,
+			UUID:     pkt.UUID.String(),
+			Username: string(pkt.Username),
+		}, nil
+	case packet.SetCompressionPacket{}.ID():
+		pkt := &packet.SetCompressionPacket{}
+		if err = pkt.Unmarshal(body); err != nil {
+			return nil, err
+		}
+
+		return &LoginResult{
+			Outcome:              LoginSetCompression,
+			CompressionThreshold: int32(pkt.Threshold),
+		}, nil
+	case packet.LoginPluginRequestPacket{}.ID():
+		pkt := &packet.LoginPluginRequestPacket{}
+		if err = pkt.Unmarshal(body); err != nil {
+			return nil, err
+		}
+
+		return &LoginResult{
+			Outcome:       LoginPluginRequest,
+			PluginChannel: string(pkt.Channel),
+			PluginData:    pkt.Data,
+		}, nil
+	}
+
+	return nil, UnexpectedPacketError{ID: packetID}
+}
+
+// Returns the UUID offline mode servers assign to the given name.
+func offlineUUID(name string) enc.UUID {
+	id := enc.UUID(md5.Sum([]byte("OfflinePlayer:" + name)))
+	id[6] = id[6]&0x0f | 0x30 // Version 3
+	id[8] = id[8]&0x3f | 0x80 // IETF variant
+	return id
+}
+
+// NewLoginProber Creates a new LoginProber with specified host & port
+// to probe the login state of a minecraft server
+func NewLoginProber(host string, port uint16, options ...McPingerOption) LoginProber {
+	return newPinger(host, port, options)
+}
+
+// WithUsername sets the player name sent when probing the login state.
+func WithUsername(name string) McPingerOption {
+	return func(p *mcPinger) {
+		p.Username = name
+	}
+}
+
+// WithUUID sets the player UUID sent when probing the login state.
+// Defaults to the offline mode UUID of the username.
+func WithUUID(uuid string) McPingerOption {
+	return func(p *mcPinger) {
+		p.UUID = uuid
+	}
+}
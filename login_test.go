@@ -0,0 +1,193 @@
+package mcpinger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"github.com/Raqbit/mc-pinger/internal/mctest"
+)
+
+func TestProbeLogin(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Reply   []byte
+		Outcome LoginOutcome
+		Check   func(t *testing.T, res *LoginResult)
+	}{
+		{
+			Name: "encryption request",
+			Reply: buildPacket(0x01, func(w io.Writer) {
+				_ = enc.WriteString(w, "")
+				_ = enc.WriteByteArray(w, []byte{0x30, 0x81})
+				_ = enc.WriteByteArray(w, []byte{0x01, 0x02, 0x03, 0x04})
+				_ = enc.WriteBoolean(w, true)
+			}),
+			Outcome: LoginEncryptionRequest,
+			Check: func(t *testing.T, res *LoginResult) {
+				if !res.OnlineMode() {
+					t.Error("server should be in online mode")
+				}
+				if !bytes.Equal(res.PublicKey, []byte{0x30, 0x81}) {
+					t.Errorf("Did not parse public key correctly: %v", res.PublicKey)
+				}
+				if !bytes.Equal(res.VerifyToken, []byte{0x01, 0x02, 0x03, 0x04}) {
+					t.Errorf("Did not parse verify token correctly: %v", res.VerifyToken)
+				}
+			},
+		},
+		{
+			Name: "disconnect",
+			Reply: buildPacket(0x00, func(w io.Writer) {
+				_ = enc.WriteString(w, `{"text":"You are not whitelisted on this server!"}`)
+			}),
+			Outcome: LoginDisconnect,
+			Check: func(t *testing.T, res *LoginResult) {
+				if res.Reason == nil || res.Reason.Text != "You are not whitelisted on this server!" {
+					t.Errorf("Did not parse disconnect reason correctly: %v", res.Reason)
+				}
+			},
+		},
+		{
+			Name: "login success",
+			Reply: buildPacket(0x02, func(w io.Writer) {
+				_ = enc.WriteUUID(w, offlineUUID("Notch"))
+				_ = enc.WriteString(w, "Notch")
+				_ = enc.WriteVarInt(w, 0)
+			}),
+			Outcome: LoginSuccess,
+			Check: func(t *testing.T, res *LoginResult) {
+				if res.UUID != "b50ad385-829d-3141-a216-7e7d7539ba7f" {
+					t.Errorf("Did not parse UUID correctly: %s", res.UUID)
+				}
+				if res.Username != "Notch" {
+					t.Errorf("Did not parse username correctly: %s", res.Username)
+				}
+			},
+		},
+		{
+			Name: "set compression",
+			Reply: buildPacket(0x03, func(w io.Writer) {
+				_ = enc.WriteVarInt(w, 256)
+			}),
+			Outcome: LoginSetCompression,
+			Check: func(t *testing.T, res *LoginResult) {
+				if res.CompressionThreshold != 256 {
+					t.Errorf("Did not parse threshold correctly: %d", res.CompressionThreshold)
+				}
+			},
+		},
+		{
+			Name: "plugin request",
+			Reply: buildPacket(0x04, func(w io.Writer) {
+				_ = enc.WriteVarInt(w, 1)
+				_ = enc.WriteString(w, "velocity:player_info")
+				_, _ = w.Write([]byte{0x04})
+			}),
+			Outcome: LoginPluginRequest,
+			Check: func(t *testing.T, res *LoginResult) {
+				if res.PluginChannel != "velocity:player_info" {
+					t.Errorf("Did not parse channel correctly: %s", res.PluginChannel)
+				}
+				if !bytes.Equal(res.PluginData, []byte{0x04}) {
+					t.Errorf("Did not parse plugin data correctly: %v", res.PluginData)
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			host, port := serveLogins(t, []byte(`{"version":{"name":"1.21","protocol":767}}`), func(hs mctest.Handshake) []byte {
+				return test.Reply
+			})
+
+			res, err := NewLoginProber(host, port, WithUsername("Notch")).ProbeLogin()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Outcome != test.Outcome {
+				t.Fatalf("Expected outcome %s, got %s", test.Outcome, res.Outcome)
+			}
+
+			test.Check(t, res)
+		})
+	}
+}
+
+func TestProbeLoginProtocol(t *testing.T) {
+	// Like vanilla, the server refuses logins of other versions than its own
+	host, port := serveLogins(t, GetTestFileContents(t, "info_description_1_20_3.json"), func(hs mctest.Handshake) []byte {
+		if hs.ProtoVer != 765 {
+			return buildPacket(0x00, func(w io.Writer) {
+				_ = enc.WriteString(w, `{"translate":"multiplayer.disconnect.outdated_client","with":["1.20.4"]}`)
+			})
+		}
+
+		return buildPacket(0x01, func(w io.Writer) {
+			_ = enc.WriteString(w, "")
+			_ = enc.WriteByteArray(w, []byte{0x30, 0x81})
+			_ = enc.WriteByteArray(w, []byte{0x01, 0x02, 0x03, 0x04})
+		})
+	})
+
+	res, err := NewClient(WithTimeout(time.Second)).ProbeLogin(context.Background(), host, port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.OnlineMode() {
+		t.Errorf("Expected the login with the reported protocol to be accepted, got %s", res.Outcome)
+	}
+}
+
+func TestProbeLoginEmptyReason(t *testing.T) {
+	host, port := serveLogins(t, []byte(`{"version":{"name":"1.21","protocol":767}}`), func(hs mctest.Handshake) []byte {
+		return buildPacket(0x00, func(w io.Writer) {
+			_ = enc.WriteString(w, "")
+		})
+	})
+
+	res, err := NewClient(WithTimeout(time.Second)).ProbeLogin(context.Background(), host, port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Outcome != LoginDisconnect || res.Reason.PlainText() != "" {
+		t.Errorf("Expected a disconnect without reason, got %s %+v", res.Outcome, res.Reason)
+	}
+}
+
+func TestOfflineUUID(t *testing.T) {
+	if id := offlineUUID("Notch").String(); id != "b50ad385-829d-3141-a216-7e7d7539ba7f" {
+		t.Errorf("Unexpected offline UUID: %s", id)
+	}
+}
+
+// Builds a raw uncompressed packet with the given ID and body.
+func buildPacket(id enc.VarInt, body func(w io.Writer)) []byte {
+	return mctest.Packet(id, body)
+}
+
+// Starts a loopback server answering status requests with the given JSON,
+// and each login with the reply to its handshake.
+func serveLogins(t *testing.T, json []byte, reply func(hs mctest.Handshake) []byte) (string, uint16) {
+	host, port, _ := mctest.Serve(t, func(conn net.Conn, hs mctest.Handshake) {
+		if hs.NextState == StatusState {
+			_, _ = conn.Write(statusReply(json))
+			return
+		}
+
+		_, _ = conn.Write(reply(hs))
+	})
+
+	return host, port
+}
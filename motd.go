@@ -0,0 +1,130 @@
+package mcpinger
+
+import "strings"
+
+const (
+	MOTDWidth = 270 // Width of the MOTD in the server list, in pixels
+	MOTDLines = 2   // Lines of the MOTD shown in the server list
+)
+
+// Width of glyphs of the default font in pixels, without the pixel between glyphs.
+// Glyphs missing are 5 pixels wide.
+var glyphWidths = map[rune]int{
+	' ': 3, '!': 1, '"': 3, '\'': 1, '(': 4, ')': 4, '*': 4, ',': 1, '.': 1, ':': 1, ';': 1,
+	'<': 4, '>': 4, '@': 6, 'I': 3, '[': 3, ']': 3, '`': 2, 'f': 4, 'i': 1, 'k': 4, 'l': 2,
+	't': 3, '{': 4, '|': 1, '}': 4,
+}
+
+const defaultGlyphWidth = 5
+
+// GlyphWidth returns the width of a character in the default font in pixels,
+// including the pixel between glyphs. Bold text is one pixel wider.
+// Characters outside of ASCII are approximated.
+func GlyphWidth(r rune, bold bool) int {
+	width, ok := glyphWidths[r]
+
+	if !ok {
+		width = defaultGlyphWidth
+	}
+
+	width++
+
+	if bold {
+		width++
+	}
+
+	return width
+}
+
+// TextWidth returns the width of a line of spans in the default font in pixels.
+func TextWidth(spans []TextSpan) int {
+	width := 0
+
+	for _, span := range spans {
+		for _, r := range span.Text {
+			width += GlyphWidth(r, span.Bold)
+		}
+	}
+
+	return width
+}
+
+// Lines returns the spans of the component split at newlines.
+func (c ChatComponent) Lines() [][]TextSpan {
+	lines := [][]TextSpan{nil}
+
+	for _, span := range c.Spans() {
+		for i, text := range strings.Split(span.Text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+
+			if text != "" {
+				span.Text = text
+				lines[len(lines)-1] = append(lines[len(lines)-1], span)
+			}
+		}
+	}
+
+	return lines
+}
+
+// MOTDLine is a line of a MOTD.
+type MOTDLine struct {
+	Spans    []TextSpan
+	Text     string // Plain text of the line
+	Width    int    // Width in pixels
+	Overflow bool   // Whether the line is wider than MOTDWidth, which wraps it onto the next line
+}
+
+// MOTDAnalysis describes how a MOTD is rendered in the server list.
+type MOTDAnalysis struct {
+	Lines    []MOTDLine
+	Overflow bool // Whether text is cut off, as a line wraps or there are more than MOTDLines lines
+}
+
+// AnalyzeMOTD splits the component into lines, measuring whether they fit the server list.
+func (c ChatComponent) AnalyzeMOTD() MOTDAnalysis {
+	var analysis MOTDAnalysis
+
+	for _, spans := range c.Lines() {
+		line := MOTDLine{Spans: spans, Width: TextWidth(spans)}
+
+		var b strings.Builder
+		for _, span := range spans {
+			b.WriteString(span.Text)
+		}
+
+		line.Text = b.String()
+		line.Overflow = line.Width > MOTDWidth
+
+		analysis.Overflow = analysis.Overflow || line.Overflow
+		analysis.Lines = append(analysis.Lines, line)
+	}
+
+	if len(analysis.Lines) > MOTDLines {
+		analysis.Overflow = true
+	}
+
+	return analysis
+}
+
+// CenterMOTD centers lines containing legacy § formatting codes in the server list
+// by prefixing them with spaces, returning them joined by newlines.
+func CenterMOTD(lines ...string) string {
+	space := GlyphWidth(' ', false)
+	centered := make([]string, len(lines))
+
+	for i, line := range lines {
+		padding := (MOTDWidth - TextWidth(appendLegacySpans(nil, line, TextSpan{}))) / 2
+
+		if padding < 0 {
+			padding = 0
+		}
+
+		// Round to the nearest amount of spaces
+		centered[i] = strings.Repeat(" ", (padding+space/2)/space) + line
+	}
+
+	return strings.Join(centered, "\n")
+}
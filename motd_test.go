@@ -0,0 +1,95 @@
+package mcpinger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		Text     string
+		Expected int
+	}{
+		{"Hello", 6 + 6 + 3 + 3 + 6},
+		{"§lHello", 7 + 7 + 4 + 4 + 7},
+		{"i l", 2 + 4 + 3},
+		{"§aA§r!", 6 + 2},
+		{"", 0},
+	}
+
+	for _, test := range tests {
+		if actual := TextWidth(appendLegacySpans(nil, test.Text, TextSpan{})); actual != test.Expected {
+			t.Errorf("Unexpected width of %q, expected %d, got %d", test.Text, test.Expected, actual)
+		}
+	}
+}
+
+func TestAnalyzeMOTD(t *testing.T) {
+	tests := []struct {
+		Json     string
+		Lines    []string
+		Overflow bool
+	}{
+		{`"§aA Minecraft Server\n§7Join now"`, []string{"A Minecraft Server", "Join now"}, false},
+		{`{"text":"Line one","extra":[{"text":"\nLine ","bold":true},"two"]}`, []string{"Line one", "Line two"}, false},
+		{`"One\nTwo\nThree"`, []string{"One", "Two", "Three"}, true},
+		{`"` + strings.Repeat("W", 46) + `"`, []string{strings.Repeat("W", 46)}, true},
+		{`"` + strings.Repeat("W", 45) + `"`, []string{strings.Repeat("W", 45)}, false},
+	}
+
+	for _, test := range tests {
+		var c ChatComponent
+
+		if err := json.Unmarshal([]byte(test.Json), &c); err != nil {
+			t.Fatalf("Unable to parse %s: %v", test.Json, err)
+		}
+
+		analysis := c.AnalyzeMOTD()
+
+		if len(analysis.Lines) != len(test.Lines) {
+			t.Errorf("Expected %d lines of %s, got %d", len(test.Lines), test.Json, len(analysis.Lines))
+			continue
+		}
+
+		for i, line := range analysis.Lines {
+			if line.Text != test.Lines[i] {
+				t.Errorf("Unexpected line %d of %s, expected %q, got %q", i, test.Json, test.Lines[i], line.Text)
+			}
+		}
+
+		if analysis.Overflow != test.Overflow {
+			t.Errorf("Expected overflow of %s to be %t", test.Json, test.Overflow)
+		}
+	}
+}
+
+func TestCenterMOTD(t *testing.T) {
+	lines := strings.Split(CenterMOTD("§6§lMy Server", "Survival"), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", lines)
+	}
+
+	for _, line := range lines {
+		spans := appendLegacySpans(nil, line, TextSpan{})
+		padding := len(line) - len(strings.TrimLeft(line, " "))
+		width := TextWidth(spans) - padding*GlyphWidth(' ', false)
+
+		// The text starts within half a space of the center
+		if left := padding * GlyphWidth(' ', false); abs(MOTDWidth-width-2*left) > GlyphWidth(' ', false) {
+			t.Errorf("Line %q is not centered: %dpx text after %dpx of padding", line, width, left)
+		}
+	}
+
+	if wide := strings.Repeat("W", 50); CenterMOTD(wide) != wide {
+		t.Error("Expected a line wider than the MOTD not to be padded")
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
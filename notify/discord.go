@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"text/template"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+// Embed colors
+const (
+	colorOnline  = 0x55ff55
+	colorOffline = 0xff5555
+	colorChange  = 0x5555ff
+)
+
+const faviconName = "favicon.png"
+
+// Discord posts events to a Discord webhook as embeds, with the favicon as thumbnail.
+// See: https://discord.com/developers/docs/resources/webhook#execute-webhook
+type Discord struct {
+	URL    string
+	Client *http.Client // Client to post with, http.DefaultClient when nil
+
+	Username string // Overrides the name of the webhook, if set
+
+	// Title & Description are executed with Data to build the embed.
+	// When nil, a title describing the event and the MOTD are used.
+	Title       *template.Template
+	Description *template.Template
+
+	Limiter *RateLimiter // Limits notifications, if set
+}
+
+type discordPayload struct {
+	Username    string              `json:"username,omitempty"`
+	Embeds      []discordEmbed      `json:"embeds"`
+	Attachments []discordAttachment `json:"attachments,omitempty"`
+}
+
+type discordEmbed struct {
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Color       int                `json:"color"`
+	Timestamp   string             `json:"timestamp,omitempty"`
+	Fields      []discordField     `json:"fields,omitempty"`
+	Thumbnail   *discordEmbedImage `json:"thumbnail,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+type discordAttachment struct {
+	ID       int    `json:"id"`
+	Filename string `json:"filename"`
+}
+
+// Notify posts the event to the Discord webhook.
+func (d *Discord) Notify(ctx context.Context, event mcpinger.Event) error {
+	if !d.Limiter.Allow() {
+		return ErrRateLimited
+	}
+
+	data := NewData(event)
+
+	title, err := execute(d.Title, data, defaultTitle(data))
+
+	if err != nil {
+		return err
+	}
+
+	defDescription := data.MOTD
+
+	if event.Type == mcpinger.WentOffline {
+		defDescription = data.Error
+	}
+
+	description, err := execute(d.Description, data, defDescription)
+
+	if err != nil {
+		return err
+	}
+
+	embed := discordEmbed{
+		Title:       title,
+		Description: description,
+		Color:       eventColor(event.Type),
+		Timestamp:   data.Time.UTC().Format(time.RFC3339),
+	}
+
+	if event.Current != nil {
+		embed.Fields = []discordField{
+			{Name: "Players", Value: fmt.Sprintf("%d/%d", data.Online, data.Max), Inline: true},
+			{Name: "Version", Value: data.Version, Inline: true},
+		}
+	}
+
+	payload := discordPayload{Username: d.Username}
+
+	var favicon []byte
+
+	if event.Current != nil {
+		// Favicons which can't be decoded are left out
+		if favicon, err = event.Current.FaviconPNG(); err != nil {
+			favicon = nil
+		}
+	}
+
+	if favicon != nil {
+		embed.Thumbnail = &discordEmbedImage{URL: "attachment://" + faviconName}
+		payload.Attachments = []discordAttachment{{ID: 0, Filename: faviconName}}
+	}
+
+	payload.Embeds = []discordEmbed{embed}
+
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return err
+	}
+
+	if favicon == nil {
+		return post(ctx, d.Client, d.URL, "application/json", body)
+	}
+
+	// Attach the favicon, referenced by the thumbnail
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	if err = mw.WriteField("payload_json", string(body)); err != nil {
+		return err
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[0]"; filename="%s"`, faviconName))
+	header.Set("Content-Type", "image/png")
+
+	part, err := mw.CreatePart(header)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = part.Write(favicon); err != nil {
+		return err
+	}
+
+	if err = mw.Close(); err != nil {
+		return err
+	}
+
+	return post(ctx, d.Client, d.URL, mw.FormDataContentType(), buf.Bytes())
+}
+
+func defaultTitle(data Data) string {
+	switch data.Event.Type {
+	case mcpinger.WentOnline:
+		return data.Host + " is online"
+	case mcpinger.WentOffline:
+		return data.Host + " went offline"
+	case mcpinger.VersionChanged:
+		return data.Host + " now runs " + data.Version
+	case mcpinger.MOTDChanged:
+		return "The MOTD of " + data.Host + " changed"
+	case mcpinger.PlayerSeen:
+		return data.Player + " joined " + data.Host
+	case mcpinger.PlayerGone:
+		return data.Player + " left " + data.Host
+	case mcpinger.FaviconChanged:
+		return "The icon of " + data.Host + " changed"
+	default:
+		return data.Host + ": " + data.Type
+	}
+}
+
+func eventColor(t mcpinger.EventType) int {
+	switch t {
+	case mcpinger.WentOnline:
+		return colorOnline
+	case mcpinger.WentOffline:
+		return colorOffline
+	default:
+		return colorChange
+	}
+}
@@ -0,0 +1,165 @@
+// Package notify posts the change events of a mcpinger.Watcher to webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+// ErrRateLimited is returned when a notification is dropped by a RateLimiter.
+var ErrRateLimited = errors.New("notification rate limited")
+
+// Notifier sends a notification for an event.
+type Notifier interface {
+	Notify(ctx context.Context, event mcpinger.Event) error
+}
+
+// StatusError is returned when a webhook responds with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Data is what notification templates are executed with.
+type Data struct {
+	Event    mcpinger.Event
+	Type     string // Type of event, like "went offline"
+	Host     string
+	Port     uint16
+	Time     time.Time
+	Version  string // Version name, empty when offline
+	Protocol int32
+	Online   int32
+	Max      int32
+	MOTD     string // Description as plain text
+	Player   string // Name of the player which was seen or is gone
+	Error    string // Error of the last ping when offline
+}
+
+// NewData Creates the template data of an event.
+func NewData(event mcpinger.Event) Data {
+	data := Data{
+		Event:  event,
+		Type:   event.Type.String(),
+		Host:   event.Host,
+		Port:   event.Port,
+		Time:   event.Time,
+		Player: event.Player.Name,
+	}
+
+	if info := event.Current; info != nil {
+		data.Version = info.Version.Name
+		data.Protocol = info.Version.Protocol
+		data.Online = info.Players.Online
+		data.Max = info.Players.Max
+		data.MOTD = info.Description.PlainText()
+	}
+
+	if event.Err != nil {
+		data.Error = event.Err.Error()
+	}
+
+	return data
+}
+
+// Dispatch sends every event received on events to all notifiers,
+// until events is closed or ctx is done.
+// Errors of notifiers are passed to onError, if set.
+func Dispatch(ctx context.Context, events <-chan mcpinger.Event, onError func(error), notifiers ...Notifier) {
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			for _, n := range notifiers {
+				if err := n.Notify(ctx, event); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Only returns a Notifier passing only events of the given types to n.
+func Only(n Notifier, types ...mcpinger.EventType) Notifier {
+	return filter{n: n, types: types}
+}
+
+type filter struct {
+	n     Notifier
+	types []mcpinger.EventType
+}
+
+func (f filter) Notify(ctx context.Context, event mcpinger.Event) error {
+	for _, t := range f.types {
+		if event.Type == t {
+			return f.n.Notify(ctx, event)
+		}
+	}
+	return nil
+}
+
+// Executes tmpl with data, or returns def when tmpl is nil.
+func execute(tmpl *template.Template, data Data, def string) (string, error) {
+	if tmpl == nil {
+		return def, nil
+	}
+
+	var b strings.Builder
+
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", errors.New("could not execute template: " + err.Error())
+	}
+
+	return b.String(), nil
+}
+
+// Posts body to url, returning a StatusError for non-2xx responses.
+func post(ctx context.Context, client *http.Client, url, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		return fmt.Errorf("could not post notification: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return StatusError{StatusCode: resp.StatusCode, Body: string(msg)}
+	}
+
+	// Drain so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
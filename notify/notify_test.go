@@ -0,0 +1,249 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+// Request received by a test webhook.
+type request struct {
+	contentType string
+	body        []byte
+}
+
+// Starts a webhook stand-in recording requests, responding with status.
+func serveWebhook(t *testing.T, status int) (string, <-chan request) {
+	requests := make(chan request, 8)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{contentType: r.Header.Get("Content-Type"), body: body}
+		w.WriteHeader(status)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv.URL, requests
+}
+
+// Returns an event as emitted by a watcher for a server which updated.
+func versionEvent(favicon []byte) mcpinger.Event {
+	info := &mcpinger.ServerInfo{Version: mcpinger.Version{Name: "1.21", Protocol: 767}}
+	info.Players.Online = 3
+	info.Players.Max = 20
+	info.Description.Text = "§aA Minecraft Server"
+
+	if favicon != nil {
+		info.Favicon = "data:image/png;base64," + base64.StdEncoding.EncodeToString(favicon)
+	}
+
+	return mcpinger.Event{
+		Type:    mcpinger.VersionChanged,
+		Time:    time.Date(2024, 6, 13, 12, 0, 0, 0, time.UTC),
+		Host:    "mc.example.com",
+		Port:    25565,
+		Current: info,
+	}
+}
+
+func TestWebhookJSON(t *testing.T) {
+	url, requests := serveWebhook(t, http.StatusNoContent)
+
+	hook := &Webhook{URL: url}
+
+	if err := hook.Notify(context.Background(), versionEvent(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-requests
+
+	if req.contentType != "application/json" {
+		t.Errorf("Unexpected content type %s", req.contentType)
+	}
+
+	var payload webhookPayload
+
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.Type != "version changed" || payload.Version != "1.21" || payload.MOTD != "A Minecraft Server" || payload.Online != 3 {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	url, requests := serveWebhook(t, http.StatusOK)
+
+	hook := &Webhook{
+		URL:  url,
+		Body: template.Must(template.New("body").Parse("{{.Host}} is now on {{.Version}} ({{.Online}}/{{.Max}})")),
+	}
+
+	if err := hook.Notify(context.Background(), versionEvent(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-requests
+
+	if string(req.body) != "mc.example.com is now on 1.21 (3/20)" {
+		t.Errorf("Unexpected body %q", req.body)
+	}
+}
+
+func TestWebhookStatusError(t *testing.T) {
+	url, _ := serveWebhook(t, http.StatusTooManyRequests)
+
+	err := (&Webhook{URL: url}).Notify(context.Background(), versionEvent(nil))
+
+	var statusErr StatusError
+
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Expected a StatusError, got %v", err)
+	}
+}
+
+func TestDiscordEmbed(t *testing.T) {
+	url, requests := serveWebhook(t, http.StatusOK)
+
+	hook := &Discord{URL: url}
+
+	if err := hook.Notify(context.Background(), versionEvent(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-requests
+
+	var payload discordPayload
+
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	embed := payload.Embeds[0]
+
+	if embed.Title != "mc.example.com now runs 1.21" || embed.Description != "A Minecraft Server" {
+		t.Errorf("Unexpected embed %+v", embed)
+	}
+
+	if embed.Thumbnail != nil {
+		t.Error("Expected no thumbnail without a favicon")
+	}
+
+	if len(embed.Fields) != 2 || embed.Fields[0].Value != "3/20" {
+		t.Errorf("Unexpected fields %+v", embed.Fields)
+	}
+}
+
+func TestDiscordFavicon(t *testing.T) {
+	url, requests := serveWebhook(t, http.StatusOK)
+
+	favicon := []byte("\x89PNG\r\n\x1a\nnot really a png")
+
+	if err := (&Discord{URL: url}).Notify(context.Background(), versionEvent(favicon)); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-requests
+
+	mediaType, params, err := mime.ParseMediaType(req.contentType)
+
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("Expected a multipart body, got %s", req.contentType)
+	}
+
+	mr := multipart.NewReader(strings.NewReader(string(req.body)), params["boundary"])
+
+	var payload discordPayload
+	var attached []byte
+
+	for {
+		part, err := mr.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := io.ReadAll(part)
+
+		switch part.FormName() {
+		case "payload_json":
+			if err = json.Unmarshal(data, &payload); err != nil {
+				t.Fatal(err)
+			}
+		case "files[0]":
+			attached = data
+		}
+	}
+
+	if string(attached) != string(favicon) {
+		t.Error("Expected the favicon to be attached")
+	}
+
+	if thumb := payload.Embeds[0].Thumbnail; thumb == nil || thumb.URL != "attachment://favicon.png" {
+		t.Errorf("Expected the thumbnail to reference the attachment, got %+v", thumb)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+
+	l := NewRateLimiter(time.Minute, 2)
+	l.now = func() time.Time { return now }
+
+	if !l.Allow() || !l.Allow() {
+		t.Fatal("Expected the burst to be allowed")
+	}
+
+	if l.Allow() {
+		t.Error("Expected to be limited after the burst")
+	}
+
+	now = now.Add(time.Minute)
+
+	if !l.Allow() {
+		t.Error("Expected a token after the interval")
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	url, requests := serveWebhook(t, http.StatusOK)
+
+	hook := &Webhook{URL: url, Limiter: NewRateLimiter(time.Hour, 1)}
+
+	events := make(chan mcpinger.Event, 3)
+	events <- mcpinger.Event{Type: mcpinger.PlayerSeen}
+	events <- versionEvent(nil)
+	events <- versionEvent(nil)
+	close(events)
+
+	var errs []error
+
+	Dispatch(context.Background(), events, func(err error) { errs = append(errs, err) },
+		Only(hook, mcpinger.WentOffline, mcpinger.VersionChanged))
+
+	if len(requests) != 1 {
+		t.Errorf("Expected 1 notification, got %d", len(requests))
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], ErrRateLimited) {
+		t.Errorf("Expected the second version change to be rate limited, got %v", errs)
+	}
+}
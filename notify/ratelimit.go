@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting how often notifications are sent.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	every  time.Duration
+	burst  int
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewRateLimiter Creates a RateLimiter allowing a notification every interval,
+// with bursts of up to burst notifications.
+func NewRateLimiter(every time.Duration, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		every:  every,
+		burst:  burst,
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Allow reports whether a notification may be sent now, taking a token if so.
+// A nil RateLimiter allows everything.
+func (l *RateLimiter) Allow() bool {
+	if l == nil {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	if !l.last.IsZero() && l.every > 0 {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.every)
+
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+
+	return true
+}
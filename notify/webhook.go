@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"text/template"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+// Webhook posts events to a generic webhook.
+type Webhook struct {
+	URL    string
+	Client *http.Client // Client to post with, http.DefaultClient when nil
+
+	// Body is executed with Data to build the request body.
+	// When nil, Data is posted as JSON.
+	Body        *template.Template
+	ContentType string // Content type of a templated body, text/plain when empty
+
+	Limiter *RateLimiter // Limits notifications, if set
+}
+
+// Payload posted when no body template is set.
+type webhookPayload struct {
+	Type     string    `json:"type"`
+	Host     string    `json:"host"`
+	Port     uint16    `json:"port"`
+	Time     time.Time `json:"time"`
+	Version  string    `json:"version,omitempty"`
+	Protocol int32     `json:"protocol,omitempty"`
+	Online   int32     `json:"online"`
+	Max      int32     `json:"max"`
+	MOTD     string    `json:"motd,omitempty"`
+	Player   string    `json:"player,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Notify posts the event to the webhook.
+func (w *Webhook) Notify(ctx context.Context, event mcpinger.Event) error {
+	if !w.Limiter.Allow() {
+		return ErrRateLimited
+	}
+
+	data := NewData(event)
+
+	if w.Body == nil {
+		body, err := json.Marshal(webhookPayload{
+			Type:     data.Type,
+			Host:     data.Host,
+			Port:     data.Port,
+			Time:     data.Time,
+			Version:  data.Version,
+			Protocol: data.Protocol,
+			Online:   data.Online,
+			Max:      data.Max,
+			MOTD:     data.MOTD,
+			Player:   data.Player,
+			Error:    data.Error,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return post(ctx, w.Client, w.URL, "application/json", body)
+	}
+
+	body, err := execute(w.Body, data, "")
+
+	if err != nil {
+		return err
+	}
+
+	contentType := w.ContentType
+
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	return post(ctx, w.Client, w.URL, contentType, []byte(body))
+}
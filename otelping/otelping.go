@@ -0,0 +1,182 @@
+// Package otelping converts the PingTrace hooks of mc-pinger into OpenTelemetry spans.
+// It is a separate module, so the OpenTelemetry dependency is only pulled in when used.
+package otelping
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Start starts a span for pinging host & port and returns a context carrying a
+// PingTrace, which records the stages of a ping made with it as child spans & events.
+// The returned function ends the span and must be called with the error of the ping.
+func Start(ctx context.Context, tracer trace.Tracer, host string, port uint16) (context.Context, func(err error)) {
+	ctx, span := tracer.Start(ctx, "mcping", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("server.address", host),
+		attribute.Int("server.port", int(port)),
+	))
+
+	r := &recorder{ctx: ctx, tracer: tracer, span: span, connects: make(map[string]trace.Span)}
+
+	end := func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// End spans of stages which were never completed
+		for _, s := range r.connects {
+			s.End()
+		}
+		if r.dns != nil {
+			r.dns.End()
+		}
+		if r.response != nil {
+			r.response.End()
+		}
+
+		setStatus(span, err)
+		span.End()
+	}
+
+	return mcpinger.WithPingTrace(ctx, r.pingTrace()), end
+}
+
+// Records PingTrace hooks as spans, hooks may be called concurrently.
+type recorder struct {
+	ctx    context.Context
+	tracer trace.Tracer
+	span   trace.Span
+
+	mu       sync.Mutex
+	dns      trace.Span
+	connects map[string]trace.Span
+	response trace.Span
+}
+
+func (r *recorder) pingTrace() *mcpinger.PingTrace {
+	return &mcpinger.PingTrace{
+		DNSStart: func(host string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			_, r.dns = r.tracer.Start(r.ctx, "dns", trace.WithAttributes(attribute.String("dns.host", host)))
+		},
+		DNSDone: func(ips []net.IP, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			if r.dns == nil {
+				return
+			}
+
+			addrs := make([]string, len(ips))
+			for i, ip := range ips {
+				addrs[i] = ip.String()
+			}
+
+			r.dns.SetAttributes(attribute.StringSlice("dns.addresses", addrs))
+			setStatus(r.dns, err)
+			r.dns.End()
+			r.dns = nil
+		},
+		SRVResolved: func(target string, port uint16, err error) {
+			attrs := []attribute.KeyValue{
+				attribute.String("srv.target", target),
+				attribute.Int("srv.port", int(port)),
+			}
+			if err != nil {
+				attrs = append(attrs, attribute.String("error", err.Error()))
+			}
+			r.span.AddEvent("srv.resolved", trace.WithAttributes(attrs...))
+		},
+		ConnectStart: func(network, addr string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			_, r.connects[addr] = r.tracer.Start(r.ctx, "connect", trace.WithAttributes(
+				attribute.String("network.transport", network),
+				attribute.String("network.peer.address", addr),
+			))
+		},
+		ConnectDone: func(network, addr string, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			if s, ok := r.connects[addr]; ok {
+				setStatus(s, err)
+				s.End()
+				delete(r.connects, addr)
+			}
+		},
+		ProxyHeaderWritten: func(err error) {
+			r.span.AddEvent("proxy_header.written", trace.WithAttributes(errorAttrs(err)...))
+		},
+		HandshakeSent: func(err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			r.span.AddEvent("handshake.sent", trace.WithAttributes(errorAttrs(err)...))
+
+			if err == nil {
+				_, r.response = r.tracer.Start(r.ctx, "response")
+			}
+		},
+		ResponseHeaderRead: func(length int32, packetID int32) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			if r.response != nil {
+				r.response.AddEvent("response.header", trace.WithAttributes(
+					attribute.Int("packet.length", int(length)),
+					attribute.Int("packet.id", int(packetID)),
+				))
+			}
+		},
+		ResponseParsed: func(info *mcpinger.ServerInfo, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			if r.response == nil {
+				return
+			}
+
+			if info != nil {
+				r.response.SetAttributes(
+					attribute.String("minecraft.version", info.Version.Name),
+					attribute.Int("minecraft.protocol", int(info.Version.Protocol)),
+					attribute.Int("minecraft.players.online", int(info.Players.Online)),
+					attribute.Int("minecraft.players.max", int(info.Players.Max)),
+				)
+			}
+
+			setStatus(r.response, err)
+			r.response.End()
+			r.response = nil
+		},
+		PongReceived: func(rtt time.Duration, err error) {
+			r.span.AddEvent("pong.received", trace.WithAttributes(append(errorAttrs(err),
+				attribute.Float64("minecraft.rtt_ms", float64(rtt)/float64(time.Millisecond)),
+			)...))
+		},
+	}
+}
+
+func setStatus(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func errorAttrs(err error) []attribute.KeyValue {
+	if err == nil {
+		return nil
+	}
+	return []attribute.KeyValue{attribute.String("error", err.Error())}
+}
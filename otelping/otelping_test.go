@@ -0,0 +1,91 @@
+package otelping
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStart(t *testing.T) {
+	host, port := serveStatus(t, `{"version":{"name":"1.21","protocol":767},"players":{"max":20,"online":1}}`)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	ctx, end := Start(context.Background(), tracer, host, port)
+
+	_, err := mcpinger.NewClient().Ping(ctx, host, port)
+	end(err)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]sdktrace.ReadOnlySpan)
+
+	for _, span := range recorder.Ended() {
+		names[span.Name()] = span
+	}
+
+	for _, name := range []string{"mcping", "connect", "response"} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("Expected a %s span, got %v", name, names)
+		}
+	}
+
+	parent := names["mcping"].SpanContext().SpanID()
+
+	if names["response"].Parent().SpanID() != parent {
+		t.Error("Expected the response span to be a child of the ping span")
+	}
+}
+
+// Starts a loopback server answering a status request with the given JSON.
+func serveStatus(t *testing.T, json string) (string, uint16) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		// Skip handshake & request
+		for i := 0; i < 2; i++ {
+			length, err := enc.ReadVarInt(conn)
+			if err != nil {
+				return
+			}
+			if _, err = io.CopyN(io.Discard, conn, int64(length)); err != nil {
+				return
+			}
+		}
+
+		var data bytes.Buffer
+		_ = enc.WriteVarInt(&data, 0x00)
+		_ = enc.WriteString(&data, enc.String(json))
+
+		_ = enc.WriteVarInt(conn, enc.VarInt(data.Len()))
+		_, _ = conn.Write(data.Bytes())
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), uint16(addr.Port)
+}
@@ -0,0 +1,202 @@
+package packet
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"io"
+)
+
+const (
+	// MaxUncompressedSize is the largest uncompressed packet size
+	// accepted when reading compressed packets.
+	MaxUncompressedSize = 8388608
+)
+
+var (
+	// ErrBadDataLength is returned when a compressed packet does not
+	// decompress to its announced data length.
+	ErrBadDataLength = errors.New("compressed packet has invalid data length")
+)
+
+// CompressedWriter converts uncompressed packets written to it, like
+// those written by WritePacket, to the compressed packet format.
+// Packets with a size of at least the threshold are zlib compressed.
+type CompressedWriter struct {
+	w         io.Writer
+	threshold int
+	buff      bytes.Buffer
+}
+
+// NewCompressedWriter creates a CompressedWriter writing to w.
+// A negative threshold disables compression, passing packets through.
+func NewCompressedWriter(w io.Writer, threshold int) *CompressedWriter {
+	return &CompressedWriter{w: w, threshold: threshold}
+}
+
+// Write buffers the passed bytes and writes out every complete packet.
+func (cw *CompressedWriter) Write(b []byte) (int, error) {
+	if cw.threshold < 0 {
+		return cw.w.Write(b)
+	}
+
+	cw.buff.Write(b)
+
+	for {
+		frame := cw.buff.Bytes()
+		length, err := enc.ReadVarInt(bytes.NewReader(frame))
+
+		if err == io.EOF {
+			// Length not complete yet
+			return len(b), nil
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		lenSize := enc.VarIntSize(length)
+
+		if len(frame) < lenSize+int(length) {
+			// Packet not complete yet
+			return len(b), nil
+		}
+
+		if err = cw.writeFrame(frame[lenSize : lenSize+int(length)]); err != nil {
+			return 0, err
+		}
+
+		cw.buff.Next(lenSize + int(length))
+	}
+}
+
+// Writes packet ID & data in the compressed packet format.
+func (cw *CompressedWriter) writeFrame(data []byte) error {
+	var out bytes.Buffer
+
+	if len(data) < cw.threshold {
+		// Write data length of 0, marking the packet as uncompressed
+		if err := enc.WriteVarInt(&out, 0); err != nil {
+			return err
+		}
+
+		out.Write(data)
+	} else {
+		// Write uncompressed data length
+		if err := enc.WriteVarInt(&out, enc.VarInt(len(data))); err != nil {
+			return err
+		}
+
+		zw := zlib.NewWriter(&out)
+
+		if _, err := zw.Write(data); err != nil {
+			return err
+		}
+
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+
+	// Write packet length
+	if err := enc.WriteVarInt(cw.w, enc.VarInt(out.Len())); err != nil {
+		return err
+	}
+
+	_, err := cw.w.Write(out.Bytes())
+	return err
+}
+
+// CompressedReader converts packets read in the compressed packet format
+// to uncompressed packets, to be read by ReadPacketHeader.
+type CompressedReader struct {
+	r       io.Reader
+	pending bytes.Buffer
+}
+
+// NewCompressedReader creates a CompressedReader reading from r.
+func NewCompressedReader(r io.Reader) *CompressedReader {
+	return &CompressedReader{r: r}
+}
+
+// Read reads uncompressed packet bytes, reading the next
+// compressed packet when the previous one was consumed.
+func (cr *CompressedReader) Read(b []byte) (int, error) {
+	if cr.pending.Len() == 0 {
+		if err := cr.readFrame(); err != nil {
+			return 0, err
+		}
+	}
+
+	return cr.pending.Read(b)
+}
+
+// Reads a compressed packet, storing it in uncompressed form.
+func (cr *CompressedReader) readFrame() error {
+	// Read packet length
+	length, err := enc.ReadVarInt(cr.r)
+
+	if err != nil {
+		return err
+	}
+
+	// Read uncompressed data length
+	dataLength, err := enc.ReadVarInt(cr.r)
+
+	if err != nil {
+		return err
+	}
+
+	remaining := int64(length) - int64(enc.VarIntSize(dataLength))
+
+	if remaining < 0 || dataLength < 0 || dataLength > MaxUncompressedSize {
+		return ErrBadDataLength
+	}
+
+	body := io.LimitReader(cr.r, remaining)
+	var data []byte
+
+	if dataLength == 0 {
+		// Packet was not compressed, its size is limited like decompressed data
+		if remaining > MaxUncompressedSize {
+			return ErrBadDataLength
+		}
+
+		data = make([]byte, remaining)
+
+		if _, err = io.ReadFull(body, data); err != nil {
+			return err
+		}
+	} else {
+		zr, err := zlib.NewReader(body)
+
+		if err != nil {
+			return err
+		}
+
+		data = make([]byte, dataLength)
+
+		if _, err = io.ReadFull(zr, data); err != nil {
+			return ErrBadDataLength
+		}
+
+		// Data must end where the announced length ends
+		if n, _ := zr.Read(make([]byte, 1)); n != 0 {
+			return ErrBadDataLength
+		}
+
+		// Discard any trailing bytes of the packet
+		if _, err = io.Copy(io.Discard, body); err != nil {
+			return err
+		}
+	}
+
+	if err = enc.WriteVarInt(&cr.pending, enc.VarInt(len(data))); err != nil {
+		return err
+	}
+
+	cr.pending.Write(data)
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package packet
+
+import (
+	"bytes"
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"io"
+	"testing"
+)
+
+// Packet with raw data for testing framing.
+type rawPacket struct {
+	id   enc.VarInt
+	data []byte
+}
+
+func (r rawPacket) ID() enc.VarInt {
+	return r.id
+}
+
+func (r rawPacket) Marshal() ([]byte, error) {
+	return r.data, nil
+}
+
+func (r rawPacket) Append(b []byte) ([]byte, error) {
+	return append(b, r.data...), nil
+}
+
+var (
+	helloWorld = bytes.Repeat([]byte("hello world "), 4)
+
+	// zlib compressed 0x00 packet ID followed by helloWorld
+	helloWorldCompressed = []byte{
+		0x18, 0x31,
+		0x78, 0x9c, 0x63, 0xc8, 0x48, 0xcd, 0xc9, 0xc9, 0x57, 0x28, 0xcf, 0x2f,
+		0xca, 0x49, 0x51, 0x20, 0x86, 0x0d, 0x00, 0xbd, 0x10, 0x11, 0xf1,
+	}
+)
+
+func TestCompressedWriter(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Threshold int
+		Packet    rawPacket
+		Expected  []byte
+	}{
+		{
+			Name:      "below threshold",
+			Threshold: 256,
+			Packet:    rawPacket{id: 0x00, data: []byte("hi")},
+			Expected:  []byte{0x04, 0x00, 0x00, 0x68, 0x69},
+		},
+		{
+			Name:      "disabled",
+			Threshold: -1,
+			Packet:    rawPacket{id: 0x00, data: []byte("hi")},
+			Expected:  []byte{0x03, 0x00, 0x68, 0x69},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var buff bytes.Buffer
+
+			err := WritePacket(test.Packet, NewCompressedWriter(&buff, test.Threshold))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(buff.Bytes(), test.Expected) {
+				t.Errorf("Unexpected compressed packet: %v != %v", buff.Bytes(), test.Expected)
+			}
+		})
+	}
+}
+
+func TestCompressedWriterAboveThreshold(t *testing.T) {
+	var buff bytes.Buffer
+
+	err := WritePacket(rawPacket{id: 0x00, data: helloWorld}, NewCompressedWriter(&buff, 16))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The compressed bytes depend on the deflate implementation,
+	// so only the data length is compared before reading it back.
+	if buff.Len() < 2 || buff.Bytes()[1] != 0x31 {
+		t.Fatalf("Unexpected data length in compressed packet: %v", buff.Bytes())
+	}
+
+	r := NewCompressedReader(&buff)
+
+	if _, _, err = ReadPacketHeader(r); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, helloWorld) {
+		t.Errorf("Unable to read back compressed packet: %q", data)
+	}
+}
+
+func TestCompressedReader(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Value    []byte
+		Expected []byte
+	}{
+		{
+			Name:     "uncompressed",
+			Value:    []byte{0x04, 0x00, 0x00, 0x68, 0x69},
+			Expected: []byte("hi"),
+		},
+		{
+			Name:     "compressed",
+			Value:    helloWorldCompressed,
+			Expected: helloWorld,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			r := NewCompressedReader(bytes.NewReader(test.Value))
+
+			length, id, err := ReadPacketHeader(r)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if id != 0x00 || int(length) != len(test.Expected)+1 {
+				t.Fatalf("Unexpected packet header: length %d, id %d", length, id)
+			}
+
+			data := make([]byte, len(test.Expected))
+
+			if _, err = io.ReadFull(r, data); err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(data, test.Expected) {
+				t.Errorf("Unexpected packet data: %q != %q", data, test.Expected)
+			}
+
+			if _, _, err = ReadPacketHeader(r); err != io.EOF {
+				t.Errorf("Expected EOF after last packet, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCompressedReaderBadDataLength(t *testing.T) {
+	value := append([]byte(nil), helloWorldCompressed...)
+	value[1] = 0x30 // Announce one byte less than compressed
+
+	_, _, err := ReadPacketHeader(NewCompressedReader(bytes.NewReader(value)))
+
+	if err != ErrBadDataLength {
+		t.Errorf("Expected ErrBadDataLength, got %v", err)
+	}
+}
+
+func TestCompressedReaderOversizedUncompressed(t *testing.T) {
+	// Uncompressed packet announcing a length of 2 GiB
+	value := []byte{0xff, 0xff, 0xff, 0xff, 0x07, 0x00}
+
+	_, _, err := ReadPacketHeader(NewCompressedReader(bytes.NewReader(value)))
+
+	if err != ErrBadDataLength {
+		t.Errorf("Expected ErrBadDataLength, got %v", err)
+	}
+}
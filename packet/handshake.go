@@ -1,9 +1,7 @@
 package packet
 
 import (
-	"bytes"
 	enc "github.com/Raqbit/mc-pinger/encoding"
-	"io"
 )
 
 type HandshakePacket struct {
@@ -14,36 +12,26 @@ type HandshakePacket struct {
 }
 
 func (h HandshakePacket) Marshal() ([]byte, error) {
-	var buffer bytes.Buffer
-	err := h.write(&buffer)
-	return buffer.Bytes(), err
+	return h.Append(nil)
 }
 
 func (HandshakePacket) ID() enc.VarInt {
 	return 0x00
 }
 
-func (h HandshakePacket) write(buffer io.Writer) error {
+func (h HandshakePacket) Append(b []byte) ([]byte, error) {
 
-	// Write protocol version
-	if err := enc.WriteVarInt(buffer, h.ProtoVer); err != nil {
-		return err
-	}
+	// Append protocol version
+	b = enc.AppendVarInt(b, h.ProtoVer)
 
-	// Write server address
-	if err := enc.WriteString(buffer, h.ServerAddr); err != nil {
-		return err
-	}
+	// Append server address
+	b = enc.AppendString(b, h.ServerAddr)
 
-	// Write server port
-	if err := enc.WriteUnsignedShort(buffer, h.ServerPort); err != nil {
-		return err
-	}
+	// Append server port
+	b = enc.AppendUnsignedShort(b, h.ServerPort)
 
-	// Write next connection state
-	if err := enc.WriteVarInt(buffer, h.NextState); err != nil {
-		return err
-	}
+	// Append next connection state
+	b = enc.AppendVarInt(b, h.NextState)
 
-	return nil
+	return b, nil
 }
@@ -0,0 +1,222 @@
+package packet
+
+import (
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"io"
+)
+
+// Protocol versions at which the login packet layouts changed.
+const (
+	protoVer1_16   = 735
+	protoVer1_19   = 759
+	protoVer1_19_3 = 761
+	protoVer1_20_2 = 764
+	protoVer1_20_5 = 766
+)
+
+// LoginStartPacket is sent by the client to start the login sequence.
+type LoginStartPacket struct {
+	Name enc.String
+	UUID enc.UUID
+
+	// Protocol version the packet is written for, this is not sent
+	// but determines the layout of the packet.
+	ProtoVer enc.VarInt
+}
+
+func (LoginStartPacket) ID() enc.VarInt {
+	return 0x00
+}
+
+func (l LoginStartPacket) Marshal() ([]byte, error) {
+	return l.Append(nil)
+}
+
+func (l LoginStartPacket) Append(b []byte) ([]byte, error) {
+
+	// Append player name
+	b = enc.AppendString(b, l.Name)
+
+	switch {
+	case l.ProtoVer >= protoVer1_20_2:
+		// Append player UUID
+		b = enc.AppendUUID(b, l.UUID)
+	case l.ProtoVer >= protoVer1_19:
+		if l.ProtoVer < protoVer1_19_3 {
+			// Append "has signature data", we never sign
+			b = enc.AppendBoolean(b, false)
+		}
+
+		if l.ProtoVer > protoVer1_19 {
+			// Append "has player UUID" and player UUID
+			b = enc.AppendBoolean(b, true)
+			b = enc.AppendUUID(b, l.UUID)
+		}
+	}
+
+	return b, nil
+}
+
+// DisconnectPacket is sent by the server when it refuses the login.
+type DisconnectPacket struct {
+	Reason enc.String // JSON chat component
+}
+
+func (DisconnectPacket) ID() enc.VarInt {
+	return 0x00
+}
+
+func (d *DisconnectPacket) Unmarshal(reader io.Reader) error {
+	// Read JSON reason
+	str, err := enc.ReadString(reader)
+
+	if err != nil {
+		return err
+	}
+
+	d.Reason = str
+
+	return nil
+}
+
+// EncryptionRequestPacket is sent by servers running in online mode.
+type EncryptionRequestPacket struct {
+	ServerID           enc.String
+	PublicKey          []byte // DER encoded public key
+	VerifyToken        []byte
+	ShouldAuthenticate enc.Boolean
+
+	// Protocol version the packet is read for, this is not received
+	// but determines the layout of the packet.
+	ProtoVer enc.VarInt
+}
+
+func (EncryptionRequestPacket) ID() enc.VarInt {
+	return 0x01
+}
+
+func (e *EncryptionRequestPacket) Unmarshal(reader io.Reader) error {
+	var err error
+
+	// Read server ID
+	if e.ServerID, err = enc.ReadString(reader); err != nil {
+		return err
+	}
+
+	// Read public key
+	if e.PublicKey, err = enc.ReadByteArray(reader); err != nil {
+		return err
+	}
+
+	// Read verify token
+	if e.VerifyToken, err = enc.ReadByteArray(reader); err != nil {
+		return err
+	}
+
+	// Servers before 1.20.5 always authenticate with Mojang
+	e.ShouldAuthenticate = true
+
+	if e.ProtoVer >= protoVer1_20_5 {
+		if e.ShouldAuthenticate, err = enc.ReadBoolean(reader); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoginSuccessPacket is sent by the server when the login succeeded.
+type LoginSuccessPacket struct {
+	UUID     enc.UUID
+	Username enc.String
+
+	// Protocol version the packet is read for, this is not received
+	// but determines the layout of the packet.
+	ProtoVer enc.VarInt
+}
+
+func (LoginSuccessPacket) ID() enc.VarInt {
+	return 0x02
+}
+
+func (l *LoginSuccessPacket) Unmarshal(reader io.Reader) error {
+	var err error
+
+	// Read player UUID, sent as a string before 1.16
+	if l.ProtoVer >= protoVer1_16 {
+		l.UUID, err = enc.ReadUUID(reader)
+	} else {
+		var str enc.String
+		if str, err = enc.ReadString(reader); err == nil {
+			l.UUID, err = enc.ParseUUID(string(str))
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Read player name
+	if l.Username, err = enc.ReadString(reader); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetCompressionPacket is sent by the server to enable packet compression.
+type SetCompressionPacket struct {
+	Threshold enc.VarInt
+}
+
+func (SetCompressionPacket) ID() enc.VarInt {
+	return 0x03
+}
+
+func (s *SetCompressionPacket) Unmarshal(reader io.Reader) error {
+	// Read compression threshold
+	threshold, err := enc.ReadVarInt(reader)
+
+	if err != nil {
+		return err
+	}
+
+	s.Threshold = threshold
+
+	return nil
+}
+
+// LoginPluginRequestPacket is sent by the server to start a custom
+// login exchange, like Velocity's modern forwarding.
+// The reader passed to Unmarshal must be limited to the packet body,
+// as the data extends to the end of the packet.
+type LoginPluginRequestPacket struct {
+	MessageID enc.VarInt
+	Channel   enc.String
+	Data      []byte
+}
+
+func (LoginPluginRequestPacket) ID() enc.VarInt {
+	return 0x04
+}
+
+func (l *LoginPluginRequestPacket) Unmarshal(reader io.Reader) error {
+	var err error
+
+	// Read message ID
+	if l.MessageID, err = enc.ReadVarInt(reader); err != nil {
+		return err
+	}
+
+	// Read channel identifier
+	if l.Channel, err = enc.ReadString(reader); err != nil {
+		return err
+	}
+
+	// Read remaining data
+	if l.Data, err = io.ReadAll(reader); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	enc "github.com/Raqbit/mc-pinger/encoding"
 	"io"
+	"sync"
 )
 
 // Represents a Minecraft packet.
@@ -17,14 +18,34 @@ type EncodablePacket interface {
 	Marshal() ([]byte, error)
 }
 
+// Packet which is able to append its encoded form to a buffer,
+// allowing it to be written without allocating.
+type AppendablePacket interface {
+	EncodablePacket
+	Append(b []byte) ([]byte, error)
+}
+
 // Packet which is able to be decoded.
 type DecodablePacket interface {
 	Packet
 	Unmarshal(reader io.Reader) error
 }
 
+// Buffers used to write appendable packets.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
 // Write a packet to the given Writer.
+// Appendable packets are written with a single Write call, without allocating.
 func WritePacket(p EncodablePacket, w io.Writer) error {
+	if ap, ok := p.(AppendablePacket); ok {
+		return writeAppendablePacket(ap, w)
+	}
+
 	// Marshal packet data
 	data, err := p.Marshal()
 
@@ -60,6 +81,50 @@ func WritePacket(p EncodablePacket, w io.Writer) error {
 	return nil
 }
 
+func writeAppendablePacket(p AppendablePacket, w io.Writer) error {
+	buff := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buff)
+
+	b, err := AppendPacket((*buff)[:0], p)
+
+	if err != nil {
+		return err
+	}
+
+	// Keep the grown buffer for the next packet
+	*buff = b
+
+	_, err = w.Write(b)
+	return err
+}
+
+// Append a packet, prefixed with its length, to the given buffer.
+func AppendPacket(b []byte, p AppendablePacket) ([]byte, error) {
+	start := len(b)
+
+	// Reserve space for the largest possible length prefix
+	b = append(b, make([]byte, enc.VarIntMaxByteSize)...)
+
+	// Append packet id & data
+	b = enc.AppendVarInt(b, p.ID())
+	b, err := p.Append(b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	length := enc.VarInt(len(b) - start - enc.VarIntMaxByteSize)
+
+	// Write the length prefix directly before the packet id,
+	// then move the packet to the start of the reserved space
+	var prefix [enc.VarIntMaxByteSize]byte
+	n := len(enc.AppendVarInt(prefix[:0], length))
+	offset := start + enc.VarIntMaxByteSize - n
+	copy(b[offset:], prefix[:n])
+
+	return append(b[:start], b[offset:]...), nil
+}
+
 // Get the packet ID of given packet in byte form.
 func getPacketIdBytes(p Packet) ([]byte, error) {
 	packetId := p.ID()
@@ -76,6 +141,7 @@ func getPacketIdBytes(p Packet) ([]byte, error) {
 }
 
 // Reads a packet header (length, version) from the given Reader.
+// Readers implementing io.ByteReader, like bufio.Reader, are read from without allocating.
 func ReadPacketHeader(r io.Reader) (enc.VarInt, enc.VarInt, error) {
 	pLen, err := enc.ReadVarInt(r)
 
@@ -0,0 +1,144 @@
+package packet
+
+import (
+	"bytes"
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"io"
+	"testing"
+)
+
+var benchHandshake = HandshakePacket{
+	ProtoVer:   -1,
+	ServerAddr: "mc.example.com",
+	ServerPort: 25565,
+	NextState:  1,
+}
+
+// Handshake packet encoded like before append-style encoding,
+// using a bytes.Buffer and one Write call per field.
+type bufferedHandshakePacket struct {
+	h HandshakePacket
+}
+
+func (b bufferedHandshakePacket) ID() enc.VarInt {
+	return b.h.ID()
+}
+
+func (b bufferedHandshakePacket) Marshal() ([]byte, error) {
+	var buffer bytes.Buffer
+	_ = enc.WriteVarInt(&buffer, b.h.ProtoVer)
+	_ = enc.WriteString(&buffer, b.h.ServerAddr)
+	_ = enc.WriteUnsignedShort(&buffer, b.h.ServerPort)
+	_ = enc.WriteVarInt(&buffer, b.h.NextState)
+	return buffer.Bytes(), nil
+}
+
+// Hides the io.ByteReader implementation of the wrapped reader.
+type plainReader struct {
+	r io.Reader
+}
+
+func (p plainReader) Read(b []byte) (int, error) {
+	return p.r.Read(b)
+}
+
+func TestWritePacket(t *testing.T) {
+	expected := []byte{
+		0x18, 0x00, 0xff, 0xff, 0xff, 0xff, 0x0f, 0x0e, 0x6d, 0x63, 0x2e, 0x65, 0x78, 0x61,
+		0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x63, 0xdd, 0x01,
+	}
+
+	for _, p := range []EncodablePacket{benchHandshake, bufferedHandshakePacket{benchHandshake}} {
+		var buff bytes.Buffer
+
+		if err := WritePacket(p, &buff); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buff.Bytes(), expected) {
+			t.Errorf("Unexpected packet %T: %v != %v", p, buff.Bytes(), expected)
+		}
+	}
+}
+
+func TestAppendPacketLongPrefix(t *testing.T) {
+	p := rawPacket{id: 0x01, data: bytes.Repeat([]byte{0xaa}, 200)}
+
+	b, err := AppendPacket([]byte{0xee}, p)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Existing content is kept, followed by a two byte length prefix
+	if !bytes.Equal(b[:4], []byte{0xee, 0xc9, 0x01, 0x01}) || len(b) != 204 {
+		t.Errorf("Unexpected packet: %v", b[:4])
+	}
+}
+
+func TestWritePacketAllocs(t *testing.T) {
+	// Passing a pointer, as boxing the struct in an interface allocates
+	p := &benchHandshake
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = WritePacket(p, io.Discard)
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations writing an appendable packet, got %.0f", allocs)
+	}
+}
+
+func TestReadPacketHeaderAllocs(t *testing.T) {
+	data := []byte{0x14, 0x00}
+	r := bytes.NewReader(data)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Reset(data)
+		_, _, _ = ReadPacketHeader(r)
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations reading from an io.ByteReader, got %.0f", allocs)
+	}
+}
+
+func BenchmarkWritePacket(b *testing.B) {
+	b.Run("buffered", func(b *testing.B) {
+		p := &bufferedHandshakePacket{benchHandshake}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = WritePacket(p, io.Discard)
+		}
+	})
+
+	b.Run("append", func(b *testing.B) {
+		p := &benchHandshake
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = WritePacket(p, io.Discard)
+		}
+	})
+}
+
+func BenchmarkReadPacketHeader(b *testing.B) {
+	data := []byte{0xff, 0xff, 0x03, 0x00}
+	r := bytes.NewReader(data)
+
+	b.Run("reader", func(b *testing.B) {
+		pr := plainReader{r}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Reset(data)
+			_, _, _ = ReadPacketHeader(pr)
+		}
+	})
+
+	b.Run("byte-reader", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Reset(data)
+			_, _, _ = ReadPacketHeader(r)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package packet
+
+import (
+	enc "github.com/Raqbit/mc-pinger/encoding"
+	"io"
+)
+
+// PingPacket is sent by the client after the status response
+// to measure the latency to the server.
+type PingPacket struct {
+	Payload enc.Long
+}
+
+func (PingPacket) ID() enc.VarInt {
+	return 0x01
+}
+
+func (p PingPacket) Marshal() ([]byte, error) {
+	return p.Append(nil)
+}
+
+func (p PingPacket) Append(b []byte) ([]byte, error) {
+	// Append payload, echoed back by the server
+	return enc.AppendLong(b, p.Payload), nil
+}
+
+// PongPacket is the answer of the server to a PingPacket.
+type PongPacket struct {
+	Payload enc.Long
+}
+
+func (PongPacket) ID() enc.VarInt {
+	return 0x01
+}
+
+func (p *PongPacket) Unmarshal(reader io.Reader) error {
+	// Read echoed payload
+	payload, err := enc.ReadLong(reader)
+
+	if err != nil {
+		return err
+	}
+
+	p.Payload = payload
+
+	return nil
+}
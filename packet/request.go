@@ -14,3 +14,8 @@ func (h RequestPacket) Marshal() ([]byte, error) {
 	// Packet does not have any content.
 	return make([]byte, 0), nil
 }
+
+func (RequestPacket) Append(b []byte) ([]byte, error) {
+	// Packet does not have any content.
+	return b, nil
+}
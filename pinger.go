@@ -1,22 +1,18 @@
 package mcpinger
 
 import (
-	"bufio"
 	"context"
-	"errors"
 	"fmt"
-	"github.com/pires/go-proxyproto"
 	"net"
-	"strconv"
 	"time"
 
 	enc "github.com/Raqbit/mc-pinger/encoding"
-	"github.com/Raqbit/mc-pinger/packet"
 )
 
 const (
 	UnknownProtoVersion = -1
 	StatusState         = 1
+	LoginState          = 2
 )
 
 // Pinger allows you to retrieve server info.
@@ -24,14 +20,13 @@ type Pinger interface {
 	Ping() (*ServerInfo, error)
 }
 
+// Pings a single server with the options of the embedded Client.
 type mcPinger struct {
+	Client
+
 	Host    string
 	Port    uint16
 	Context context.Context
-	Timeout time.Duration
-
-	UseProxy     bool
-	ProxyVersion byte
 }
 
 // InvalidPacketError returned when the received packet type
@@ -46,144 +41,20 @@ func (i InvalidPacketError) Error() string {
 }
 
 func (p *mcPinger) Ping() (*ServerInfo, error) {
-	if p.Timeout > 0 && p.Context == nil {
-		ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
-		p.Context = ctx
-		defer cancel()
-	}
-	if p.Context == nil {
-		p.Context = context.Background()
-	}
-	return p.ping()
-}
-
-// Will connect to the Minecraft server,
-// retrieve server status and return the server info.
-func (p *mcPinger) ping() (*ServerInfo, error) {
-
-	if p.Context == nil {
-		panic("Context is nil!")
-	}
-
-	address := net.JoinHostPort(p.Host, strconv.Itoa(int(p.Port)))
-
-	var d net.Dialer
-
-	conn, err := d.DialContext(p.Context, "tcp", address)
-
-	if err != nil {
-		return nil, errors.New("could not connect to Minecraft server: " + err.Error())
-	}
-
-	if p.UseProxy {
-		err = p.writeProxyHeader(conn)
-		if err != nil {
-			return nil, errors.New("could not write PROXY header: " + err.Error())
-		}
-	}
-
-	rd := bufio.NewReader(conn)
-	w := bufio.NewWriter(conn)
-
-	defer conn.Close()
-
-	err = p.sendHandshakePacket(w)
-
-	if err != nil {
-		return nil, err
-	}
-
-	err = p.sendRequestPacket(w)
-
-	if err != nil {
-		return nil, err
-	}
-
-	err = w.Flush()
-
-	if err != nil {
-		return nil, err
-	}
-
-	if p.Timeout > 0 {
-		// When a remote process is bound, but paused, the connect succeeds without context timeout;
-		// however, the response packet just never comes back.
-		_ = conn.SetReadDeadline(time.Now().Add(p.Timeout))
-	}
-	res, err := p.readPacket(rd)
-
-	if err != nil {
-		return nil, err
-	}
-
-	info, err := parseServerInfo([]byte(res.Json))
-
-	return info, err
-}
-
-func (p *mcPinger) sendHandshakePacket(w *bufio.Writer) error {
-	handshakePkt := &packet.HandshakePacket{
-		ProtoVer:   UnknownProtoVersion,
-		ServerAddr: enc.String(p.Host),
-		ServerPort: enc.UnsignedShort(p.Port),
-		NextState:  StatusState,
-	}
-
-	err := packet.WritePacket(handshakePkt, w)
-
-	if err != nil {
-		return errors.New("could not pack: " + err.Error())
-	}
-
-	return nil
-}
-
-func (p *mcPinger) sendRequestPacket(w *bufio.Writer) error {
-	requestPkt := &packet.RequestPacket{}
-
-	err := packet.WritePacket(requestPkt, w)
-
-	if err != nil {
-		return errors.New("could not pack: " + err.Error())
-	}
-
-	return nil
-}
-
-func (p *mcPinger) readPacket(rd *bufio.Reader) (*packet.ResponsePacket, error) {
-
-	rp := &packet.ResponsePacket{}
-
-	_, packetID, err := packet.ReadPacketHeader(rd)
-
-	if packetID != rp.ID() {
-		return nil, InvalidPacketError{expected: rp.ID(), actual: packetID}
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	err = rp.Unmarshal(rd)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return rp, nil
-}
-
-func (p *mcPinger) writeProxyHeader(conn net.Conn) error {
-	header := proxyproto.HeaderProxyFromAddrs(p.ProxyVersion, conn.LocalAddr(), conn.RemoteAddr())
-
-	_, err := header.WriteTo(conn)
-	return err
+	return p.Client.Ping(p.Context, p.Host, p.Port)
 }
 
 // New Creates a new Pinger with specified host & port
 // to connect to a minecraft server
 func New(host string, port uint16, options ...McPingerOption) Pinger {
+	return newPinger(host, port, options)
+}
+
+func newPinger(host string, port uint16, options []McPingerOption) *mcPinger {
 	p := &mcPinger{
+		Client: Client{
+			ProtoVersion: UnknownProtoVersion,
+		},
 		Host: host,
 		Port: port,
 	}
@@ -224,10 +95,92 @@ func WithContext(ctx context.Context) McPingerOption {
 }
 
 // WithProxyProto enables support for Bungeecord's proxy_protocol feature, which listens for
-// PROXY protocol connections via HAproxy. version must be 1 (text) or 2 (binary).
-func WithProxyProto(version byte) McPingerOption {
+// PROXY protocol connections via HAproxy. version must be 1 (text) or 2 (binary),
+// other versions fail when connecting. The header can be customized with ProxyOptions.
+func WithProxyProto(version byte, options ...ProxyOption) McPingerOption {
 	return func(p *mcPinger) {
 		p.UseProxy = true
 		p.ProxyVersion = version
+
+		for _, option := range options {
+			option(&p.Client)
+		}
+	}
+}
+
+// WithProtocolVersion sets the protocol version sent in the handshake.
+// Status pings default to UnknownProtoVersion, login probes to DefaultLoginProtoVersion.
+func WithProtocolVersion(version int32) McPingerOption {
+	return func(p *mcPinger) {
+		p.ProtoVersion = version
+	}
+}
+
+// WithDialer sets the Dialer used to connect to servers.
+func WithDialer(d *net.Dialer) McPingerOption {
+	return func(p *mcPinger) {
+		p.Dialer = d
+	}
+}
+
+// WithResolver sets the Resolver used to look up server hosts.
+func WithResolver(r *net.Resolver) McPingerOption {
+	return func(p *mcPinger) {
+		p.Resolver = r
+	}
+}
+
+// WithDialTimeout sets the timeout of resolving the host and connecting.
+func WithDialTimeout(timeout time.Duration) McPingerOption {
+	return func(p *mcPinger) {
+		p.DialTimeout = timeout
+	}
+}
+
+// WithWriteTimeout sets the timeout of writing the PROXY header and handshake.
+func WithWriteTimeout(timeout time.Duration) McPingerOption {
+	return func(p *mcPinger) {
+		p.WriteTimeout = timeout
+	}
+}
+
+// WithReadTimeout sets the timeout of reading the status response.
+func WithReadTimeout(timeout time.Duration) McPingerOption {
+	return func(p *mcPinger) {
+		p.ReadTimeout = timeout
+	}
+}
+
+// WithLatency enables measuring the latency with a ping/pong exchange after
+// the status response, reading the pong with the given timeout.
+func WithLatency(pongTimeout time.Duration) McPingerOption {
+	return func(p *mcPinger) {
+		p.Latency = true
+		p.PongTimeout = pongTimeout
+	}
+}
+
+// WithSRV enables looking up the _minecraft._tcp SRV record of the host,
+// connecting to its target like the Minecraft client does.
+func WithSRV() McPingerOption {
+	return func(p *mcPinger) {
+		p.SRV = true
+	}
+}
+
+// WithHappyEyeballs enables racing connections to the IPv6 and IPv4
+// addresses of a host, using the first connection established.
+func WithHappyEyeballs() McPingerOption {
+	return func(p *mcPinger) {
+		p.HappyEyeballs = true
+	}
+}
+
+// WithRetry enables retrying failed pings according to the policy.
+// Retries share the Timeout with the first attempt, use the per-phase
+// timeouts to give up on a single attempt sooner.
+func WithRetry(policy RetryPolicy) McPingerOption {
+	return func(p *mcPinger) {
+		p.Retry = &policy
 	}
 }
@@ -0,0 +1,221 @@
+package mcpinger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	enc "github.com/Raqbit/mc-pinger/encoding"
+)
+
+// DefaultPresenceWindow is how long a player counts as online after last being seen.
+const DefaultPresenceWindow = 5 * time.Minute
+
+// DefaultPresenceRetention is how many windows players are remembered for after last being seen.
+const DefaultPresenceRetention = 12
+
+// PlayerPresence is what a PresenceTracker knows about a player.
+type PlayerPresence struct {
+	Player    Player
+	FirstSeen time.Time // Time the player was first seen in a sample
+	LastSeen  time.Time // Time the player was last seen in a sample
+	Sightings int       // Amount of samples the player was seen in
+}
+
+// PresenceTracker aggregates the player samples of repeated pings.
+// Servers with more than 12 players only return a rotating sample,
+// so a single ping never sees everyone.
+// It is safe for concurrent use, and can be fed by a Watcher's OnResult hook.
+type PresenceTracker struct {
+	// Window is how long a player counts as online after last being seen.
+	// It should span enough pings for the sample to rotate through all players.
+	Window time.Duration
+
+	// Retention is how long players are remembered after last being seen,
+	// DefaultPresenceRetention windows when zero. It is never shorter than the window.
+	Retention time.Duration
+
+	mu        sync.Mutex
+	players   map[string]*PlayerPresence
+	online    int32 // Players online at the last observation
+	observed  time.Time
+	lastPrune time.Time
+}
+
+// NewPresenceTracker Creates a new PresenceTracker with the given window.
+func NewPresenceTracker(window time.Duration) *PresenceTracker {
+	return &PresenceTracker{Window: window}
+}
+
+// Observe adds the player sample of info seen at now.
+// A nil info records the server as offline.
+func (p *PresenceTracker) Observe(info *ServerInfo, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.observed = now
+	p.prune(now)
+
+	if info == nil {
+		p.online = 0
+		return
+	}
+
+	p.online = info.Players.Online
+
+	if p.players == nil {
+		p.players = make(map[string]*PlayerPresence)
+	}
+
+	for _, player := range info.Players.Sample {
+		if IsFakePlayer(player) {
+			continue
+		}
+
+		id := normalizeUUID(player.ID)
+		presence, ok := p.players[id]
+
+		if !ok {
+			presence = &PlayerPresence{FirstSeen: now}
+			p.players[id] = presence
+		}
+
+		// Players may change their name
+		presence.Player = player
+		presence.LastSeen = now
+		presence.Sightings++
+	}
+}
+
+// Forgets players not seen within the retention, p.mu must be held.
+// Players are only pruned once per window, so observing stays cheap.
+func (p *PresenceTracker) prune(now time.Time) {
+	if now.Sub(p.lastPrune) < p.window() {
+		return
+	}
+
+	p.lastPrune = now
+	retention := p.retention()
+
+	for id, presence := range p.players {
+		if now.Sub(presence.LastSeen) > retention {
+			delete(p.players, id)
+		}
+	}
+}
+
+// LastSeen returns the presence of the player with the given UUID, if seen within the retention.
+func (p *PresenceTracker) LastSeen(id string) (PlayerPresence, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	presence, ok := p.players[normalizeUUID(id)]
+
+	if !ok {
+		return PlayerPresence{}, false
+	}
+
+	return *presence, true
+}
+
+// ProbablyOnline reports whether the player with the given UUID was seen
+// within the window and the server was online with players at the last observation.
+func (p *PresenceTracker) ProbablyOnline(id string, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.online <= 0 {
+		return false
+	}
+
+	presence, ok := p.players[normalizeUUID(id)]
+
+	return ok && now.Sub(presence.LastSeen) <= p.window()
+}
+
+// Online returns the players seen within the window, most recently seen first.
+func (p *PresenceTracker) Online(now time.Time) []PlayerPresence {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.online <= 0 {
+		return nil
+	}
+
+	var online []PlayerPresence
+
+	for _, presence := range p.players {
+		if now.Sub(presence.LastSeen) <= p.window() {
+			online = append(online, *presence)
+		}
+	}
+
+	sort.Slice(online, func(i, j int) bool {
+		return online[i].LastSeen.After(online[j].LastSeen)
+	})
+
+	return online
+}
+
+// Coverage estimates the fraction of online players which are known,
+// comparing the players seen within the window to the reported online count.
+// It returns 1 when no players are online.
+func (p *PresenceTracker) Coverage(now time.Time) float64 {
+	known := len(p.Online(now))
+
+	p.mu.Lock()
+	online := p.online
+	p.mu.Unlock()
+
+	if online <= 0 {
+		return 1
+	}
+
+	// Players which left within the window are still counted
+	if int32(known) >= online {
+		return 1
+	}
+
+	return float64(known) / float64(online)
+}
+
+func (p *PresenceTracker) window() time.Duration {
+	if p.Window <= 0 {
+		return DefaultPresenceWindow
+	}
+	return p.Window
+}
+
+func (p *PresenceTracker) retention() time.Duration {
+	if p.Retention <= 0 {
+		return DefaultPresenceRetention * p.window()
+	}
+	if p.Retention < p.window() {
+		return p.window()
+	}
+	return p.Retention
+}
+
+// IsFakePlayer reports whether a sample entry is not a real player,
+// like the entries servers use to show lines of text when hovering the player count.
+func IsFakePlayer(player Player) bool {
+	if player.Name == "" || strings.Contains(player.Name, "§") {
+		return true
+	}
+
+	uuid, err := enc.ParseUUID(player.ID)
+
+	return err != nil || uuid == enc.UUID{}
+}
+
+// Returns the UUID in its hyphenated lowercase form, so both forms match.
+func normalizeUUID(id string) string {
+	uuid, err := enc.ParseUUID(id)
+
+	if err != nil {
+		return id
+	}
+
+	return uuid.String()
+}
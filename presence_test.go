@@ -0,0 +1,122 @@
+package mcpinger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsFakePlayer(t *testing.T) {
+	tests := []struct {
+		player   Player
+		expected bool
+	}{
+		{Player{Name: "Notch", ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}, false},
+		{Player{Name: "Notch", ID: "069a79f444e94726a5befca90e38aaf5"}, false},
+		{Player{Name: "§aWelcome to the server!", ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}, true},
+		{Player{Name: "Visit our website", ID: "00000000-0000-0000-0000-000000000000"}, true},
+		{Player{Name: "Not a UUID", ID: "hello"}, true},
+		{Player{Name: "", ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}, true},
+	}
+
+	for _, test := range tests {
+		if actual := IsFakePlayer(test.player); actual != test.expected {
+			t.Errorf("IsFakePlayer(%+v) = %t, expected %t", test.player, actual, test.expected)
+		}
+	}
+}
+
+func TestPresenceTracker(t *testing.T) {
+	notch := Player{Name: "Notch", ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}
+	jeb := Player{Name: "jeb_", ID: "853c80ef-3c37-49fd-aa49-938b674adae6"}
+	fake := Player{Name: "§6Play now!", ID: "00000000-0000-0000-0000-000000000000"}
+
+	sample := func(online int32, players ...Player) *ServerInfo {
+		info := new(ServerInfo)
+		info.Players.Online = online
+		info.Players.Sample = players
+		return info
+	}
+
+	start := time.Now()
+	tracker := NewPresenceTracker(time.Minute)
+
+	// Rotating sample of a server with 4 players
+	tracker.Observe(sample(4, notch, fake), start)
+	tracker.Observe(sample(4, jeb, fake), start.Add(10*time.Second))
+
+	now := start.Add(20 * time.Second)
+
+	if !tracker.ProbablyOnline(notch.ID, now) || !tracker.ProbablyOnline("853c80ef3c3749fdaa49938b674adae6", now) {
+		t.Error("Expected both sampled players to probably be online")
+	}
+
+	if tracker.ProbablyOnline(fake.ID, now) {
+		t.Error("Expected fake players to be filtered")
+	}
+
+	if coverage := tracker.Coverage(now); coverage != 0.5 {
+		t.Errorf("Expected a coverage of 0.5, got %f", coverage)
+	}
+
+	if online := tracker.Online(now); len(online) != 2 || online[0].Player != jeb {
+		t.Errorf("Expected jeb_ to be the most recently seen of 2 players, got %+v", online)
+	}
+
+	// Notch falls out of the window
+	later := start.Add(65 * time.Second)
+
+	if tracker.ProbablyOnline(notch.ID, later) {
+		t.Error("Expected Notch to no longer be online after the window")
+	}
+
+	presence, ok := tracker.LastSeen(notch.ID)
+
+	if !ok || !presence.LastSeen.Equal(start) || presence.Sightings != 1 {
+		t.Errorf("Unexpected presence of Notch: %+v", presence)
+	}
+
+	// Server went offline
+	tracker.Observe(nil, later)
+
+	if tracker.ProbablyOnline(jeb.ID, later) {
+		t.Error("Expected nobody to be online on an offline server")
+	}
+
+	if coverage := tracker.Coverage(later); coverage != 1 {
+		t.Errorf("Expected full coverage when nobody is online, got %f", coverage)
+	}
+}
+
+func TestPresenceTrackerRetention(t *testing.T) {
+	notch := Player{Name: "Notch", ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}
+	jeb := Player{Name: "jeb_", ID: "853c80ef-3c37-49fd-aa49-938b674adae6"}
+
+	start := time.Now()
+	tracker := NewPresenceTracker(time.Minute)
+	tracker.Retention = 10 * time.Minute
+
+	info := new(ServerInfo)
+	info.Players.Online = 1
+	info.Players.Sample = []Player{notch}
+	tracker.Observe(info, start)
+
+	info = new(ServerInfo)
+	info.Players.Online = 1
+	info.Players.Sample = []Player{jeb}
+	tracker.Observe(info, start.Add(5*time.Minute))
+
+	if _, ok := tracker.LastSeen(notch.ID); !ok {
+		t.Error("Expected Notch to be remembered within the retention")
+	}
+
+	// Observing the server offline prunes too
+	tracker.Observe(nil, start.Add(11*time.Minute))
+
+	if _, ok := tracker.LastSeen(notch.ID); ok {
+		t.Error("Expected Notch to be forgotten after the retention")
+	}
+
+	if _, ok := tracker.LastSeen(jeb.ID); !ok {
+		t.Error("Expected jeb_ to be remembered within the retention")
+	}
+}
@@ -0,0 +1,124 @@
+package mcpinger
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/pires/go-proxyproto/tlvparse"
+)
+
+// Maximum length of a unique ID TLV
+// See: https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt
+const maxProxyUniqueIDLength = 128
+
+// ProxyOption customizes the PROXY header written by WithProxyProto.
+type ProxyOption func(c *Client)
+
+// ProxySource sets the source address of the PROXY header,
+// to test what a backend sees for a specific player address.
+func ProxySource(addr *net.TCPAddr) ProxyOption {
+	return func(c *Client) {
+		c.ProxySource = addr
+	}
+}
+
+// ProxyDestination sets the destination address of the PROXY header.
+func ProxyDestination(addr *net.TCPAddr) ProxyOption {
+	return func(c *Client) {
+		c.ProxyDestination = addr
+	}
+}
+
+// ProxyTLV adds a TLV to the PROXY header, which requires version 2.
+func ProxyTLV(typ proxyproto.PP2Type, value []byte) ProxyOption {
+	return func(c *Client) {
+		c.ProxyTLVs = append(c.ProxyTLVs, proxyproto.TLV{Type: typ, Value: value})
+	}
+}
+
+// ProxyAuthority adds the host name the client connected to, like TLS SNI, to the PROXY header.
+func ProxyAuthority(host string) ProxyOption {
+	return ProxyTLV(proxyproto.PP2_TYPE_AUTHORITY, []byte(host))
+}
+
+// ProxyUniqueID adds a connection ID of at most 128 bytes to the PROXY header.
+func ProxyUniqueID(id []byte) ProxyOption {
+	return ProxyTLV(proxyproto.PP2_TYPE_UNIQUE_ID, id)
+}
+
+// ProxySSL adds the TLS details of the client connection to the PROXY header.
+func ProxySSL(ssl tlvparse.PP2SSL) ProxyOption {
+	return func(c *Client) {
+		tlv, err := ssl.Marshal()
+
+		if err != nil {
+			c.proxyErr = errors.New("could not marshal PROXY SSL TLV: " + err.Error())
+			return
+		}
+
+		c.ProxyTLVs = append(c.ProxyTLVs, tlv)
+	}
+}
+
+// Builds the PROXY header of conn, validating the version, addresses & TLVs.
+func (c *Client) proxyHeader(conn net.Conn) (*proxyproto.Header, error) {
+	if c.proxyErr != nil {
+		return nil, c.proxyErr
+	}
+
+	if c.ProxyVersion != 1 && c.ProxyVersion != 2 {
+		return nil, fmt.Errorf("invalid PROXY protocol version %d, must be 1 or 2", c.ProxyVersion)
+	}
+
+	if c.ProxyVersion == 1 && len(c.ProxyTLVs) > 0 {
+		return nil, errors.New("PROXY protocol TLVs require version 2")
+	}
+
+	var source, destination net.Addr = conn.LocalAddr(), conn.RemoteAddr()
+
+	if c.ProxySource != nil {
+		source = c.ProxySource
+	}
+
+	if c.ProxyDestination != nil {
+		destination = c.ProxyDestination
+	}
+
+	if c.ProxySource != nil || c.ProxyDestination != nil {
+		src, srcOk := source.(*net.TCPAddr)
+		dst, dstOk := destination.(*net.TCPAddr)
+
+		if !srcOk || !dstOk || (src.IP.To4() == nil) != (dst.IP.To4() == nil) {
+			return nil, fmt.Errorf("PROXY source %s and destination %s must be TCP addresses of the same family", source, destination)
+		}
+	}
+
+	for _, tlv := range c.ProxyTLVs {
+		if tlv.Type == proxyproto.PP2_TYPE_UNIQUE_ID && len(tlv.Value) > maxProxyUniqueIDLength {
+			return nil, fmt.Errorf("PROXY unique ID is %d bytes, more than %d", len(tlv.Value), maxProxyUniqueIDLength)
+		}
+	}
+
+	header := proxyproto.HeaderProxyFromAddrs(c.ProxyVersion, source, destination)
+
+	if len(c.ProxyTLVs) > 0 {
+		if err := header.SetTLVs(c.ProxyTLVs); err != nil {
+			return nil, err
+		}
+	}
+
+	return header, nil
+}
+
+func (c *Client) writeProxyHeader(conn net.Conn) error {
+	header, err := c.proxyHeader(conn)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = header.WriteTo(conn)
+	return err
+}
@@ -0,0 +1,120 @@
+package mcpinger
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/pires/go-proxyproto/tlvparse"
+)
+
+// Starts a loopback server sending the PROXY header of each connection on the returned channel.
+func serveProxy(t *testing.T) (string, uint16, <-chan *proxyproto.Header) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	headers := make(chan *proxyproto.Header, 1)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+
+			if err != nil {
+				return
+			}
+
+			header, err := proxyproto.Read(bufio.NewReader(conn))
+			conn.Close()
+
+			if err == nil {
+				headers <- header
+			}
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), uint16(addr.Port), headers
+}
+
+func TestProxyHeader(t *testing.T) {
+	host, port, headers := serveProxy(t)
+
+	source := &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 54321}
+	destination := &net.TCPAddr{IP: net.ParseIP("198.51.100.1"), Port: 25565}
+
+	client := NewClient(WithTimeout(time.Second), WithProxyProto(2,
+		ProxySource(source),
+		ProxyDestination(destination),
+		ProxyAuthority("mc.example.com"),
+		ProxyUniqueID([]byte("connection-1")),
+		ProxySSL(tlvparse.PP2SSL{Client: tlvparse.PP2_BITFIELD_CLIENT_SSL}),
+	))
+
+	_, _ = client.Ping(context.Background(), host, port)
+
+	header := <-headers
+
+	src, dst, ok := header.TCPAddrs()
+
+	if !ok || src.String() != source.String() || dst.String() != destination.String() {
+		t.Errorf("Expected addresses %s -> %s, got %v -> %v", source, destination, header.SourceAddr, header.DestinationAddr)
+	}
+
+	tlvs, err := header.TLVs()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []proxyproto.PP2Type{proxyproto.PP2_TYPE_AUTHORITY, proxyproto.PP2_TYPE_UNIQUE_ID, proxyproto.PP2_TYPE_SSL}
+
+	if len(tlvs) != len(expected) {
+		t.Fatalf("Expected %d TLVs, got %d", len(expected), len(tlvs))
+	}
+
+	for i, typ := range expected {
+		if tlvs[i].Type != typ {
+			t.Errorf("Expected TLV %d to be of type %#x, got %#x", i, typ, tlvs[i].Type)
+		}
+	}
+
+	if string(tlvs[0].Value) != "mc.example.com" {
+		t.Errorf("Unexpected authority %q", tlvs[0].Value)
+	}
+}
+
+func TestProxyHeaderInvalid(t *testing.T) {
+	host, port, _ := serveProxy(t)
+
+	v6 := &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 54321}
+
+	tests := []struct {
+		Name   string
+		Option McPingerOption
+		Error  string
+	}{
+		{"version 0", WithProxyProto(0), "invalid PROXY protocol version"},
+		{"version 3", WithProxyProto(3), "invalid PROXY protocol version"},
+		{"TLVs with version 1", WithProxyProto(1, ProxyAuthority("mc.example.com")), "require version 2"},
+		{"mixed families", WithProxyProto(2, ProxySource(v6)), "same family"},
+		{"long unique ID", WithProxyProto(2, ProxyUniqueID(make([]byte, 129))), "unique ID"},
+	}
+
+	for _, test := range tests {
+		_, err := NewClient(WithTimeout(time.Second), test.Option).Ping(context.Background(), host, port)
+
+		if err == nil || !strings.Contains(err.Error(), test.Error) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.Name, test.Error, err)
+		}
+	}
+}
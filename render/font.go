@@ -0,0 +1,114 @@
+package render
+
+// Hand-made bitmap font resembling the default Minecraft font.
+// Glyphs are 8 rows high: 7 rows above the baseline and a row for descenders.
+// Their widths match mcpinger.GlyphWidth, which adds the pixel between glyphs.
+var glyphs = map[rune][]string{
+	' ':  {"..."},
+	'!':  {"#", "#", "#", "#", "#", ".", "#"},
+	'"':  {"#.#", "#.#"},
+	'#':  {".#.#.", ".#.#.", "#####", ".#.#.", "#####", ".#.#.", ".#.#."},
+	'$':  {"..#..", ".####", "#....", ".###.", "....#", "####.", "..#.."},
+	'%':  {"##..#", "##.#.", "...#.", "..#..", ".#...", ".#.##", "#..##"},
+	'&':  {".##..", "#..#.", ".##..", ".##.#", "#..#.", "#..#.", ".##.#"},
+	'\'': {"#", "#"},
+	'(':  {"..##", ".#..", "#...", "#...", "#...", ".#..", "..##"},
+	')':  {"##..", "..#.", "...#", "...#", "...#", "..#.", "##.."},
+	'*':  {"....", "....", "#..#", ".##.", "#..#"},
+	'+':  {".....", "..#..", "..#..", "#####", "..#..", "..#.."},
+	',':  {".", ".", ".", ".", ".", "#", "#", "#"},
+	'-':  {".....", ".....", ".....", "#####"},
+	'.':  {".", ".", ".", ".", ".", "#", "#"},
+	'/':  {"....#", "...#.", "...#.", "..#..", ".#...", ".#...", "#...."},
+	'0':  {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1':  {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", "#####"},
+	'2':  {".###.", "#...#", "....#", "..##.", ".#...", "#...#", "#####"},
+	'3':  {".###.", "#...#", "....#", "..##.", "....#", "#...#", ".###."},
+	'4':  {"...##", "..#.#", ".#..#", "#...#", "#####", "....#", "....#"},
+	'5':  {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6':  {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7':  {"#####", "#...#", "....#", "...#.", "..#..", "..#..", "..#.."},
+	'8':  {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9':  {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	':':  {".", "#", "#", ".", ".", "#", "#"},
+	';':  {".", "#", "#", ".", ".", "#", "#", "#"},
+	'<':  {"...#", "..#.", ".#..", "#...", ".#..", "..#.", "...#"},
+	'=':  {".....", ".....", "#####", ".....", ".....", "#####"},
+	'>':  {"#...", ".#..", "..#.", "...#", "..#.", ".#..", "#..."},
+	'?':  {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+	'@':  {".####.", "#....#", "#.##.#", "#.##.#", "#.###.", "#.....", ".####."},
+	'A':  {".###.", "#...#", "#####", "#...#", "#...#", "#...#", "#...#"},
+	'B':  {"####.", "#...#", "####.", "#...#", "#...#", "#...#", "####."},
+	'C':  {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D':  {"####.", "#...#", "#...#", "#...#", "#...#", "#...#", "####."},
+	'E':  {"#####", "#....", "###..", "#....", "#....", "#....", "#####"},
+	'F':  {"#####", "#....", "###..", "#....", "#....", "#....", "#...."},
+	'G':  {".####", "#....", "#..##", "#...#", "#...#", "#...#", ".###."},
+	'H':  {"#...#", "#...#", "#####", "#...#", "#...#", "#...#", "#...#"},
+	'I':  {"###", ".#.", ".#.", ".#.", ".#.", ".#.", "###"},
+	'J':  {"....#", "....#", "....#", "....#", "....#", "#...#", ".###."},
+	'K':  {"#...#", "#..#.", "###..", "#..#.", "#...#", "#...#", "#...#"},
+	'L':  {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M':  {"#...#", "##.##", "#.#.#", "#...#", "#...#", "#...#", "#...#"},
+	'N':  {"#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#", "#...#"},
+	'O':  {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P':  {"####.", "#...#", "####.", "#....", "#....", "#....", "#...."},
+	'Q':  {".###.", "#...#", "#...#", "#...#", "#...#", "#..#.", ".##.#"},
+	'R':  {"####.", "#...#", "####.", "#...#", "#...#", "#...#", "#...#"},
+	'S':  {".####", "#....", ".###.", "....#", "....#", "#...#", ".###."},
+	'T':  {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U':  {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V':  {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W':  {"#...#", "#...#", "#...#", "#...#", "#.#.#", "##.##", "#...#"},
+	'X':  {"#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#", "#...#"},
+	'Y':  {"#...#", ".#.#.", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'Z':  {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+	'[':  {"###", "#..", "#..", "#..", "#..", "#..", "###"},
+	'\\': {"#....", ".#...", ".#...", "..#..", "...#.", "...#.", "....#"},
+	']':  {"###", "..#", "..#", "..#", "..#", "..#", "###"},
+	'^':  {"..#..", ".#.#.", "#...#"},
+	'_':  {".....", ".....", ".....", ".....", ".....", ".....", ".....", "#####"},
+	'`':  {"#.", ".#"},
+	'a':  {".....", ".....", ".###.", "....#", ".####", "#...#", ".####"},
+	'b':  {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "####."},
+	'c':  {".....", ".....", ".###.", "#...#", "#....", "#...#", ".###."},
+	'd':  {"....#", "....#", ".##.#", "#..##", "#...#", "#...#", ".####"},
+	'e':  {".....", ".....", ".###.", "#...#", "#####", "#....", ".####"},
+	'f':  {"..##", ".#..", "####", ".#..", ".#..", ".#..", ".#.."},
+	'g':  {".....", ".....", ".####", "#...#", "#...#", ".####", "....#", "####."},
+	'h':  {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+	'i':  {"#", ".", "#", "#", "#", "#", "#"},
+	'j':  {"....#", ".....", "....#", "....#", "....#", "#...#", "#...#", ".###."},
+	'k':  {"#...", "#...", "#..#", "#.#.", "##..", "#.#.", "#..#"},
+	'l':  {"#.", "#.", "#.", "#.", "#.", "#.", ".#"},
+	'm':  {".....", ".....", "##.#.", "#.#.#", "#.#.#", "#...#", "#...#"},
+	'n':  {".....", ".....", "####.", "#...#", "#...#", "#...#", "#...#"},
+	'o':  {".....", ".....", ".###.", "#...#", "#...#", "#...#", ".###."},
+	'p':  {".....", ".....", "#.##.", "##..#", "#...#", "####.", "#....", "#...."},
+	'q':  {".....", ".....", ".##.#", "#..##", "#...#", ".####", "....#", "....#"},
+	'r':  {".....", ".....", "#.##.", "##..#", "#....", "#....", "#...."},
+	's':  {".....", ".....", ".####", "#....", ".###.", "....#", "####."},
+	't':  {".#.", ".#.", "###", ".#.", ".#.", ".#.", "..#"},
+	'u':  {".....", ".....", "#...#", "#...#", "#...#", "#...#", ".####"},
+	'v':  {".....", ".....", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'w':  {".....", ".....", "#...#", "#...#", "#.#.#", "#.#.#", ".####"},
+	'x':  {".....", ".....", "#...#", ".#.#.", "..#..", ".#.#.", "#...#"},
+	'y':  {".....", ".....", "#...#", "#...#", "#...#", ".####", "....#", "####."},
+	'z':  {".....", ".....", "#####", "...#.", "..#..", ".#...", "#####"},
+	'{':  {"..##", ".#..", ".#..", "#...", ".#..", ".#..", "..##"},
+	'|':  {"#", "#", "#", "#", "#", "#", "#", "#"},
+	'}':  {"##..", "..#.", "..#.", "...#", "..#.", "..#.", "##.."},
+	'~':  {".....", ".....", ".##.#", "#.##."},
+}
+
+// Drawn for characters missing from the font
+var missingGlyph = []string{"#####", "#...#", "#...#", "#...#", "#...#", "#...#", "#####"}
+
+// Returns the glyph of a character.
+func glyph(r rune) []string {
+	if g, ok := glyphs[r]; ok {
+		return g
+	}
+
+	return missingGlyph
+}
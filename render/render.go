@@ -0,0 +1,293 @@
+// Package render draws servers as entries of the in-game server list,
+// for websites & chat embeds. Text is drawn with a bundled bitmap font resembling Minecraft's.
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+const (
+	Width        = 305 // Width of an entry in unscaled pixels
+	Height       = 36  // Height of an entry in unscaled pixels
+	DefaultScale = 2
+
+	DefaultName = "Minecraft Server"
+
+	lineHeight     = 9
+	faviconSize    = 32
+	maxFaviconSize = 64 // Favicons of the vanilla server, larger ones are not decoded
+	textX          = 2 + faviconSize + 3
+)
+
+// Colors of the server list
+var (
+	nameColor     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	motdColor     = color.RGBA{0x80, 0x80, 0x80, 0xff}
+	offlineColor  = color.RGBA{0xaa, 0x00, 0x00, 0xff}
+	barColor      = color.RGBA{0x00, 0xff, 0x21, 0xff}
+	emptyBarColor = color.RGBA{0x3b, 0x3b, 0x3b, 0xff}
+	faviconColor  = color.RGBA{0x8b, 0x8b, 0x8b, 0xff}
+)
+
+// Options of a rendered entry.
+type Options struct {
+	Name       string        // Server name above the MOTD, DefaultName when empty
+	Latency    time.Duration // Latency shown by the bars, no bars when zero
+	Scale      int           // Size of a pixel, DefaultScale when zero
+	Background color.Color   // Background color, transparent when nil
+}
+
+// Entry draws a server list entry of the server, which is offline when info is nil.
+func Entry(info *mcpinger.ServerInfo, opts Options) *image.RGBA {
+	scale := opts.Scale
+
+	if scale <= 0 {
+		scale = DefaultScale
+	}
+
+	name := opts.Name
+
+	if name == "" {
+		name = DefaultName
+	}
+
+	c := &canvas{img: image.NewRGBA(image.Rect(0, 0, Width*scale, Height*scale)), scale: scale}
+
+	if opts.Background != nil {
+		bg := color.RGBAModel.Convert(opts.Background).(color.RGBA)
+		c.fill(0, 0, Width, Height, bg)
+	}
+
+	c.favicon(info)
+	c.text(textX, 3, []mcpinger.TextSpan{{Text: name}}, nameColor, Width-textX-40)
+
+	if info == nil {
+		c.text(textX, 14, []mcpinger.TextSpan{{Text: "Can't connect to server"}}, offlineColor, mcpinger.MOTDWidth)
+		c.bars(Width-13, 2, 0)
+		return c.img
+	}
+
+	for i, line := range info.Description.Lines() {
+		if i == mcpinger.MOTDLines {
+			break
+		}
+
+		c.text(textX, 14+i*lineHeight, line, motdColor, mcpinger.MOTDWidth)
+	}
+
+	players := []mcpinger.TextSpan{
+		{Text: strconv.Itoa(int(info.Players.Online)), Color: "gray"},
+		{Text: "/" + strconv.Itoa(int(info.Players.Max)), Color: "dark_gray"},
+	}
+
+	c.text(Width-15-mcpinger.TextWidth(players), 3, players, motdColor, Width)
+	c.bars(Width-13, 2, opts.Latency)
+
+	return c.img
+}
+
+// EncodePNG draws a server list entry of the server like Entry, encoded as PNG.
+func EncodePNG(w io.Writer, info *mcpinger.ServerInfo, opts Options) error {
+	return png.Encode(w, Entry(info, opts))
+}
+
+// Image drawn on in unscaled pixels
+type canvas struct {
+	img   *image.RGBA
+	scale int
+}
+
+func (c *canvas) fill(x, y, w, h int, col color.RGBA) {
+	for py := y * c.scale; py < (y+h)*c.scale; py++ {
+		for px := x * c.scale; px < (x+w)*c.scale; px++ {
+			c.img.SetRGBA(px, py, col)
+		}
+	}
+}
+
+// Draws the favicon, or a placeholder when the server has none.
+func (c *canvas) favicon(info *mcpinger.ServerInfo) {
+	var icon image.Image
+
+	if info != nil {
+		if data, err := info.FaviconPNG(); err == nil {
+			icon = decodeFavicon(data)
+		}
+	}
+
+	if icon == nil {
+		c.fill(2, 2, faviconSize, faviconSize, faviconColor)
+		return
+	}
+
+	// Nearest neighbour scaling
+	size := faviconSize * c.scale
+	bounds := icon.Bounds()
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			src := icon.At(bounds.Min.X+x*bounds.Dx()/size, bounds.Min.Y+y*bounds.Dy()/size)
+			c.img.Set(2*c.scale+x, 2*c.scale+y, src)
+		}
+	}
+}
+
+// Decodes a favicon, nil when it is invalid or too large.
+// The dimensions are checked first, as the server controls how much memory decoding takes.
+func decodeFavicon(data []byte) image.Image {
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil || cfg.Width > maxFaviconSize || cfg.Height > maxFaviconSize {
+		return nil
+	}
+
+	icon, err := png.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return nil
+	}
+
+	return icon
+}
+
+// Draws the latency bars, all empty for an unknown latency.
+func (c *canvas) bars(x, y int, latency time.Duration) {
+	heights := []int{2, 3, 5, 6, 8}
+	filled := 0
+
+	switch {
+	case latency <= 0:
+	case latency < 150*time.Millisecond:
+		filled = 5
+	case latency < 300*time.Millisecond:
+		filled = 4
+	case latency < 600*time.Millisecond:
+		filled = 3
+	case latency < time.Second:
+		filled = 2
+	default:
+		filled = 1
+	}
+
+	for i, h := range heights {
+		col := emptyBarColor
+
+		if i < filled {
+			col = barColor
+		}
+
+		c.fill(x+i*2, y+8-h, 1, h, col)
+	}
+}
+
+// Draws a line of spans, clipped to maxWidth.
+func (c *canvas) text(x, y int, spans []mcpinger.TextSpan, fallback color.RGBA, maxWidth int) {
+	start, n := x, 0
+
+	for _, span := range spans {
+		col := parseColor(span.Color, fallback)
+
+		for _, r := range span.Text {
+			advance := mcpinger.GlyphWidth(r, span.Bold)
+
+			if x+advance-start > maxWidth {
+				return
+			}
+
+			if span.Obfuscated {
+				r = obfuscate(r, n)
+			}
+
+			c.glyph(x, y, glyph(r), col, span.Italic)
+
+			if span.Bold {
+				c.glyph(x+1, y, glyph(r), col, span.Italic)
+			}
+
+			if span.Underlined {
+				c.fill(x-1, y+lineHeight-1, advance+1, 1, col)
+			}
+
+			if span.Strikethrough {
+				c.fill(x-1, y+3, advance+1, 1, col)
+			}
+
+			x += advance
+			n++
+		}
+	}
+}
+
+func (c *canvas) glyph(x, y int, rows []string, col color.RGBA, italic bool) {
+	for row, line := range rows {
+		shift := 0
+
+		// Italic glyphs lean right above their middle
+		if italic && row < 4 {
+			shift = 1
+		}
+
+		for i, p := range line {
+			if p == '#' {
+				c.fill(x+i+shift, y+row, 1, 1, col)
+			}
+		}
+	}
+}
+
+// Characters of the font by glyph width, to replace obfuscated characters with
+var glyphsByWidth = func() map[int][]rune {
+	byWidth := make(map[int][]rune)
+
+	for r, g := range glyphs {
+		if r != ' ' {
+			byWidth[len(g[0])] = append(byWidth[len(g[0])], r)
+		}
+	}
+
+	for _, runes := range byWidth {
+		sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	}
+
+	return byWidth
+}()
+
+// Replaces an obfuscated character with one of the same width, chosen by its
+// position so the same text always renders the same.
+func obfuscate(r rune, position int) rune {
+	candidates := glyphsByWidth[len(glyph(r)[0])]
+
+	if len(candidates) == 0 || r == ' ' {
+		return r
+	}
+
+	return candidates[(int(r)*31+position*17)%len(candidates)]
+}
+
+// Parses a color name or hex color, returning fallback when not a color.
+func parseColor(name string, fallback color.RGBA) color.RGBA {
+	if hex, ok := mcpinger.ColorHex[name]; ok {
+		name = hex
+	}
+
+	if len(name) != 7 || name[0] != '#' {
+		return fallback
+	}
+
+	v, err := strconv.ParseUint(name[1:], 16, 32)
+
+	if err != nil {
+		return fallback
+	}
+
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
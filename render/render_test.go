@@ -0,0 +1,161 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+func TestGlyphs(t *testing.T) {
+	for r := rune(' '); r <= '~'; r++ {
+		g, ok := glyphs[r]
+
+		if !ok {
+			t.Errorf("Missing glyph %q", r)
+			continue
+		}
+
+		if len(g) > 8 {
+			t.Errorf("Glyph %q has %d rows, more than 8", r, len(g))
+		}
+
+		for _, row := range g {
+			if len(row) != len(g[0]) {
+				t.Errorf("Glyph %q has rows of different widths", r)
+			}
+		}
+
+		// The font matches the widths used to measure text
+		if expected := mcpinger.GlyphWidth(r, false) - 1; len(g[0]) != expected {
+			t.Errorf("Glyph %q is %d pixels wide, expected %d", r, len(g[0]), expected)
+		}
+	}
+}
+
+// Returns a server with a solid colored favicon.
+func testServer(t *testing.T, motd string) *mcpinger.ServerInfo {
+	info := &mcpinger.ServerInfo{Favicon: testFavicon(t, 64)}
+	info.Description.Text = motd
+	info.Players.Online, info.Players.Max = 3, 20
+
+	return info
+}
+
+// Returns the data URI of a solid red favicon of the given size.
+func testFavicon(t *testing.T, size int) string {
+	icon := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for i := range icon.Pix {
+		if i%4 == 0 || i%4 == 3 {
+			icon.Pix[i] = 0xff
+		}
+	}
+
+	var b bytes.Buffer
+
+	if err := png.Encode(&b, icon); err != nil {
+		t.Fatal(err)
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b.Bytes())
+}
+
+// Reports whether the unscaled region contains a pixel of the color.
+func contains(img *image.RGBA, scale int, region image.Rectangle, col color.RGBA) bool {
+	for y := region.Min.Y * scale; y < region.Max.Y*scale; y++ {
+		for x := region.Min.X * scale; x < region.Max.X*scale; x++ {
+			if img.RGBAAt(x, y) == col {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func TestEntry(t *testing.T) {
+	img := Entry(testServer(t, "§cRed §lbold\n§9Blue"), Options{Name: "Test", Latency: 100 * time.Millisecond, Scale: 2})
+
+	if size := img.Bounds().Size(); size != image.Pt(Width*2, Height*2) {
+		t.Fatalf("Unexpected size %v", size)
+	}
+
+	tests := []struct {
+		Name   string
+		Region image.Rectangle
+		Color  color.RGBA
+	}{
+		{"favicon", image.Rect(2, 2, 34, 34), color.RGBA{0xff, 0, 0, 0xff}},
+		{"name", image.Rect(textX, 3, textX+40, 11), nameColor},
+		{"first MOTD line", image.Rect(textX, 14, Width, 23), color.RGBA{0xff, 0x55, 0x55, 0xff}},
+		{"second MOTD line", image.Rect(textX, 23, Width, 32), color.RGBA{0x55, 0x55, 0xff, 0xff}},
+		{"player count", image.Rect(Width-60, 3, Width-14, 11), color.RGBA{0xaa, 0xaa, 0xaa, 0xff}},
+		{"latency bars", image.Rect(Width-13, 2, Width-3, 10), barColor},
+	}
+
+	for _, test := range tests {
+		if !contains(img, 2, test.Region, test.Color) {
+			t.Errorf("Expected the %s to be drawn in %v", test.Name, test.Color)
+		}
+	}
+
+	// The second line is not drawn over the first
+	if contains(img, 2, image.Rect(textX, 14, Width, 23), color.RGBA{0x55, 0x55, 0xff, 0xff}) {
+		t.Error("Expected the first MOTD line not to contain the second")
+	}
+}
+
+func TestEntryLargeFavicon(t *testing.T) {
+	info := testServer(t, "")
+	info.Favicon = testFavicon(t, maxFaviconSize+1)
+
+	img := Entry(info, Options{Scale: 1})
+
+	if !contains(img, 1, image.Rect(2, 2, 34, 34), faviconColor) {
+		t.Error("Expected the placeholder for a favicon larger than the vanilla one")
+	}
+}
+
+func TestEntryOffline(t *testing.T) {
+	img := Entry(nil, Options{Scale: 1})
+
+	if !contains(img, 1, image.Rect(textX, 14, Width, 23), offlineColor) {
+		t.Error("Expected the offline message to be drawn")
+	}
+
+	if contains(img, 1, image.Rect(Width-13, 2, Width-3, 10), barColor) {
+		t.Error("Expected empty latency bars")
+	}
+}
+
+func TestEntryObfuscated(t *testing.T) {
+	render := func(motd string) []byte {
+		var b bytes.Buffer
+
+		if err := EncodePNG(&b, testServer(t, motd), Options{}); err != nil {
+			t.Fatal(err)
+		}
+
+		return b.Bytes()
+	}
+
+	obfuscated := render("§kSecret text")
+
+	if !bytes.Equal(obfuscated, render("§kSecret text")) {
+		t.Error("Expected obfuscated text to render deterministically")
+	}
+
+	if bytes.Equal(obfuscated, render("Secret text")) {
+		t.Error("Expected obfuscated text to render differently than plain text")
+	}
+
+	if _, err := png.Decode(bytes.NewReader(obfuscated)); err != nil {
+		t.Errorf("Unable to decode rendered PNG: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultRetryAttempts  = 3
+	DefaultRetryBaseDelay = 100 * time.Millisecond
+	DefaultRetryMaxDelay  = 2 * time.Second
+)
+
+// RetryPolicy describes when and how often failed pings are retried,
+// waiting with exponential backoff between attempts. Zero fields use defaults.
+type RetryPolicy struct {
+	MaxAttempts int           // Maximum amount of attempts, including the first
+	BaseDelay   time.Duration // Delay before the first retry, doubled every retry
+	MaxDelay    time.Duration // Maximum delay between attempts
+	Jitter      float64       // Fraction of the delay which is randomized, between 0 and 1
+
+	// Retryable returns whether the error of an attempt may be retried,
+	// defaults to IsTransient.
+	Retryable func(err error) bool
+}
+
+// RetryError is returned when a ping still failed after retrying.
+type RetryError struct {
+	Attempts int   // Amount of attempts made
+	Err      error // Error of the last attempt
+}
+
+func (r RetryError) Error() string {
+	return fmt.Sprintf("failed after %d attempts: %s", r.Attempts, r.Err)
+}
+
+func (r RetryError) Unwrap() error {
+	return r.Err
+}
+
+// IsTransient returns whether err is likely to go away when retrying:
+// timeouts, reset or aborted connections, and connections closed before
+// the response arrived. Protocol errors like InvalidPacketError,
+// refused connections and cancelled contexts are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Calls attempt until it succeeds, the error is not retryable, the attempts
+// run out or the next delay would exceed the context deadline.
+// A nil policy makes a single attempt.
+func (r *RetryPolicy) do(ctx context.Context, attempt func() (*Result, error)) (*Result, error) {
+	policy := r.withDefaults()
+
+	for attempts := 1; ; attempts++ {
+		res, err := attempt()
+
+		if err == nil {
+			res.Attempts = attempts
+			return res, nil
+		}
+
+		if attempts >= policy.MaxAttempts || !policy.Retryable(err) || ctx.Err() != nil {
+			return nil, retryError(attempts, err)
+		}
+
+		delay := policy.backoff(attempts)
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return nil, retryError(attempts, err)
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, retryError(attempts, err)
+		}
+	}
+}
+
+// Wraps the error in a RetryError when more than one attempt was made.
+func retryError(attempts int, err error) error {
+	if attempts == 1 {
+		return err
+	}
+	return RetryError{Attempts: attempts, Err: err}
+}
+
+// Returns a copy of the policy with defaults filled in.
+func (r *RetryPolicy) withDefaults() RetryPolicy {
+	if r == nil {
+		return RetryPolicy{MaxAttempts: 1}
+	}
+
+	policy := *r
+
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = DefaultRetryAttempts
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = DefaultRetryBaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = DefaultRetryMaxDelay
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsTransient
+	}
+
+	return policy
+}
+
+// Returns the delay after the given attempt failed.
+func (r RetryPolicy) backoff(attempt int) time.Duration {
+	delay := r.BaseDelay
+
+	for i := 1; i < attempt && delay < r.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > r.MaxDelay {
+		delay = r.MaxDelay
+	}
+
+	if r.Jitter > 0 {
+		delay -= time.Duration(r.Jitter * randFloat64() * float64(delay))
+	}
+
+	return delay
+}
+
+var (
+	jitterRand   = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterRandMu sync.Mutex
+)
+
+func randFloat64() float64 {
+	jitterRandMu.Lock()
+	defer jitterRandMu.Unlock()
+	return jitterRand.Float64()
+}
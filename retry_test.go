@@ -0,0 +1,144 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Raqbit/mc-pinger/internal/mctest"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		Err       error
+		Transient bool
+	}{
+		{Err: nil, Transient: false},
+		{Err: io.EOF, Transient: true},
+		{Err: fmt.Errorf("could not connect to Minecraft server: %w", &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}), Transient: true},
+		{Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, Transient: true},
+		{Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, Transient: false},
+		{Err: InvalidPacketError{expected: 0x00, actual: 0x05}, Transient: false},
+		{Err: context.Canceled, Transient: false},
+		{Err: errors.New("invalid character 'x' looking for beginning of value"), Transient: false},
+	}
+
+	for _, test := range tests {
+		if actual := IsTransient(test.Err); actual != test.Transient {
+			t.Errorf("IsTransient(%v) = %t, expected %t", test.Err, actual, test.Transient)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	expected := []time.Duration{100, 200, 400, 800, 1000, 1000}
+
+	for i, delay := range expected {
+		if actual := policy.backoff(i + 1); actual != delay*time.Millisecond {
+			t.Errorf("Unexpected delay after attempt %d: %s", i+1, actual)
+		}
+	}
+
+	policy.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		if actual := policy.backoff(1); actual < 50*time.Millisecond || actual > 100*time.Millisecond {
+			t.Fatalf("Jittered delay out of range: %s", actual)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	json := GetTestFileContents(t, "info.json")
+
+	// Closes the first connection before answering
+	host, port, conns := serveSequence(t, nil, statusReply(json))
+
+	client := NewClient(WithRetry(RetryPolicy{BaseDelay: time.Millisecond}))
+
+	res, err := client.Query(context.Background(), host, port)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Attempts != 2 || atomic.LoadInt32(conns) != 2 {
+		t.Errorf("Expected 2 attempts, got %d (%d connections)", res.Attempts, atomic.LoadInt32(conns))
+	}
+}
+
+func TestRetryProtocolError(t *testing.T) {
+	invalid := buildPacket(0x05, func(w io.Writer) {})
+
+	host, port, conns := serveSequence(t, invalid, invalid)
+
+	client := NewClient(WithRetry(RetryPolicy{BaseDelay: time.Millisecond}))
+
+	_, err := client.Query(context.Background(), host, port)
+
+	if !errors.As(err, new(InvalidPacketError)) {
+		t.Fatalf("Expected an InvalidPacketError, got %v", err)
+	}
+
+	if atomic.LoadInt32(conns) != 1 {
+		t.Errorf("Expected no retries, got %d connections", atomic.LoadInt32(conns))
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	host, port, _ := serveSequence(t, nil, nil, nil)
+
+	client := NewClient(WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+
+	_, err := client.Query(context.Background(), host, port)
+
+	var retryErr RetryError
+
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 || !errors.Is(err, io.EOF) {
+		t.Errorf("Expected a RetryError after 3 attempts wrapping io.EOF, got %v", err)
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	host, port, conns := serveSequence(t, nil, nil, nil, nil, nil)
+
+	// The second delay runs past the timeout
+	client := NewClient(WithTimeout(300*time.Millisecond), WithRetry(RetryPolicy{MaxAttempts: 5, BaseDelay: 200 * time.Millisecond}))
+
+	start := time.Now()
+	_, err := client.Query(context.Background(), host, port)
+
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("Expected retries to stop within the timeout, took %s", elapsed)
+	}
+
+	if n := atomic.LoadInt32(conns); n != 2 || err == nil {
+		t.Errorf("Expected 2 failed attempts, got %d connections and %v", n, err)
+	}
+}
+
+func statusReply(json []byte) []byte {
+	return mctest.StatusResponse(json)
+}
+
+// Starts a loopback server answering each connection with the next reply,
+// closing the connection without answering for nil replies or when out of replies.
+// The amount of accepted connections is returned.
+func serveSequence(t *testing.T, replies ...[]byte) (string, uint16, *int32) {
+	var next int32
+
+	return mctest.Serve(t, func(conn net.Conn, hs mctest.Handshake) {
+		if i := int(atomic.AddInt32(&next, 1)) - 1; i < len(replies) && replies[i] != nil {
+			_, _ = conn.Write(replies[i])
+		}
+	})
+}
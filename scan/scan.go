@@ -0,0 +1,378 @@
+// Package scan discovers Minecraft servers in IP ranges, for auditing your own IP space.
+// It makes a fast TCP connect pass over all addresses & ports, pings the open ones,
+// and optionally probes for legacy & Bedrock Edition servers.
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+)
+
+const (
+	DefaultPort           = 25565
+	DefaultConnectTimeout = time.Second
+	DefaultBedrockTimeout = 2 * time.Second
+	DefaultConcurrency    = 64
+	DefaultMaxAddresses   = 1 << 20
+)
+
+// Editions of the servers found.
+const (
+	EditionJava       = "java"
+	EditionJavaLegacy = "java-legacy" // Only answered the legacy ping
+	EditionBedrock    = "bedrock"
+)
+
+// Scanner scans IP ranges for Minecraft servers.
+// Connections are limited by the Limiter of the Client, which should be set
+// to avoid tripping the anti-DDoS of the scanned networks.
+type Scanner struct {
+	Ranges  []string // CIDR ranges or IP addresses to scan
+	Exclude []string // CIDR ranges or IP addresses to skip
+	Ports   []uint16 // Java Edition ports to scan, DefaultPort when empty
+
+	Client         *mcpinger.Client // Client to ping with, a default Client when nil
+	ConnectTimeout time.Duration    // Timeout of the connect pass, DefaultConnectTimeout when zero
+	Concurrency    int              // Concurrent probes, DefaultConcurrency when zero
+	MaxAddresses   int              // Maximum addresses to scan, DefaultMaxAddresses when zero
+
+	Legacy bool // Probe open ports not answering the status ping with the legacy ping
+
+	Bedrock        bool          // Probe for Bedrock Edition servers over UDP
+	BedrockPorts   []uint16      // Bedrock Edition ports to probe, the default Bedrock port when empty
+	BedrockTimeout time.Duration // Timeout of a Bedrock probe, DefaultBedrockTimeout when zero
+}
+
+// Result is a port found open.
+type Result struct {
+	IP       string    `json:"ip"`
+	Port     uint16    `json:"port"`
+	Edition  string    `json:"edition,omitempty"` // Edition of the server, empty when not Minecraft
+	Time     time.Time `json:"time"`
+	Latency  float64   `json:"latency_ms,omitempty"` // Time to the first response byte in milliseconds
+	Version  string    `json:"version,omitempty"`
+	Protocol int32     `json:"protocol,omitempty"`
+	Online   int32     `json:"online"`
+	Max      int32     `json:"max"`
+	MOTD     string    `json:"motd,omitempty"`  // Description as plain text
+	Error    string    `json:"error,omitempty"` // Why an open port did not answer like a Minecraft server
+}
+
+// A port to probe.
+type probe struct {
+	ip      net.IP
+	port    uint16
+	bedrock bool
+}
+
+// Run scans the ranges, sending a Result for every open port on the returned channel,
+// which is closed when done or ctx is done.
+// Invalid ranges or too many addresses are reported before scanning.
+func (s *Scanner) Run(ctx context.Context) (<-chan Result, error) {
+	ranges, err := parseRanges(s.Ranges)
+
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := parseRanges(s.Exclude)
+
+	if err != nil {
+		return nil, err
+	}
+
+	maxAddresses := s.MaxAddresses
+
+	if maxAddresses <= 0 {
+		maxAddresses = DefaultMaxAddresses
+	}
+
+	total := new(big.Int)
+
+	for _, r := range ranges {
+		ones, bits := r.Mask.Size()
+		total.Add(total, new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)))
+	}
+
+	if total.Cmp(big.NewInt(int64(maxAddresses))) > 0 {
+		return nil, fmt.Errorf("ranges contain %s addresses, more than the maximum of %d", total, maxAddresses)
+	}
+
+	ports := s.Ports
+
+	if len(ports) == 0 {
+		ports = []uint16{DefaultPort}
+	}
+
+	bedrockPorts := s.BedrockPorts
+
+	if len(bedrockPorts) == 0 {
+		bedrockPorts = []uint16{mcpinger.DefaultBedrockPort}
+	}
+
+	client := s.Client
+
+	if client == nil {
+		client = mcpinger.NewClient(mcpinger.WithTimeout(5 * time.Second))
+	}
+
+	concurrency := s.Concurrency
+
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	probes := make(chan probe)
+	results := make(chan Result, concurrency)
+
+	go func() {
+		defer close(probes)
+
+		for _, r := range ranges {
+			for ip := r.IP.Mask(r.Mask); r.Contains(ip); ip = nextIP(ip) {
+				if contains(exclude, ip) {
+					continue
+				}
+
+				for _, port := range ports {
+					select {
+					case probes <- probe{ip: ip, port: port}:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				if !s.Bedrock {
+					continue
+				}
+
+				for _, port := range bedrockPorts {
+					select {
+					case probes <- probe{ip: ip, port: port, bedrock: true}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for p := range probes {
+				res, ok := s.probe(ctx, client, p)
+
+				if !ok {
+					continue
+				}
+
+				select {
+				case results <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results, nil
+}
+
+// Probes a port, returning whether it is open.
+func (s *Scanner) probe(ctx context.Context, client *mcpinger.Client, p probe) (Result, bool) {
+	res := Result{IP: p.ip.String(), Port: p.port, Time: time.Now()}
+
+	if p.bedrock {
+		timeout := s.BedrockTimeout
+
+		if timeout <= 0 {
+			timeout = DefaultBedrockTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		info, err := client.PingBedrock(ctx, res.IP, p.port)
+
+		if err != nil {
+			return res, false
+		}
+
+		res.Edition = EditionBedrock
+		res.Version, res.Protocol = info.Version, info.Protocol
+		res.Online, res.Max = info.Online, info.Max
+		res.MOTD = info.MOTD
+
+		return res, true
+	}
+
+	if !s.connect(ctx, client, p) {
+		return res, false
+	}
+
+	status, err := client.Query(ctx, res.IP, p.port)
+
+	if err == nil {
+		info := status.Info
+
+		res.Edition = EditionJava
+		res.Latency = float64(status.Timing.FirstByte) / float64(time.Millisecond)
+		res.Version, res.Protocol = info.Version.Name, info.Version.Protocol
+		res.Online, res.Max = info.Players.Online, info.Players.Max
+		res.MOTD = info.Description.PlainText()
+
+		return res, true
+	}
+
+	if s.Legacy {
+		if info, legacyErr := client.PingLegacy(ctx, res.IP, p.port); legacyErr == nil {
+			res.Edition = EditionJavaLegacy
+			res.Version, res.Protocol = info.Version, info.Protocol
+			res.Online, res.Max = info.Online, info.Max
+			res.MOTD = info.MOTD
+
+			return res, true
+		}
+	}
+
+	res.Error = err.Error()
+
+	return res, true
+}
+
+// Reports whether a TCP connection to the port can be made.
+func (s *Scanner) connect(ctx context.Context, client *mcpinger.Client, p probe) bool {
+	timeout := s.ConnectTimeout
+
+	if timeout <= 0 {
+		timeout = DefaultConnectTimeout
+	}
+
+	var dialer net.Dialer
+
+	if client.Dialer != nil {
+		dialer = *client.Dialer
+	}
+
+	dialer.Timeout = timeout
+
+	if client.Limiter != nil {
+		release, err := client.Limiter.Wait(ctx, p.ip)
+
+		if err != nil {
+			return false
+		}
+
+		defer release()
+	}
+
+	// Refusals are not reported to the limiter, as most ports of a scan are closed
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(p.ip.String(), fmt.Sprint(p.port)))
+
+	if err != nil {
+		return false
+	}
+
+	conn.Close()
+
+	return true
+}
+
+// WriteJSONL writes the results as JSON lines, returning the amount written.
+func WriteJSONL(w io.Writer, results <-chan Result) (int, error) {
+	encoder := json.NewEncoder(w)
+	n := 0
+
+	for res := range results {
+		if err := encoder.Encode(res); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// Parses CIDR ranges & IP addresses.
+func parseRanges(ranges []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(ranges))
+
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+
+		if r == "" {
+			continue
+		}
+
+		if !strings.Contains(r, "/") {
+			ip := net.ParseIP(r)
+
+			if ip == nil {
+				return nil, errors.New("invalid IP address: " + r)
+			}
+
+			if ip4 := ip.To4(); ip4 != nil {
+				nets = append(nets, &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)})
+			} else {
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+			}
+
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
+
+func contains(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the IP address following ip, which wraps around to zero.
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+
+		if next[i] != 0 {
+			break
+		}
+	}
+
+	return next
+}
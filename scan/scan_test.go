@@ -0,0 +1,301 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	mcpinger "github.com/Raqbit/mc-pinger"
+	enc "github.com/Raqbit/mc-pinger/encoding"
+)
+
+const statusJson = `{"version":{"name":"1.13.2","protocol":404},"players":{"max":20,"online":3},"description":{"text":"A Minecraft Server"}}`
+
+var raknetMagic = []byte{0x00, 0xFF, 0xFF, 0x00, 0xFE, 0xFE, 0xFE, 0xFE, 0xFD, 0xFD, 0xFD, 0xFD, 0x12, 0x34, 0x56, 0x78}
+
+// Starts a loopback TCP server handling connections with handle, returning its port.
+func serve(t *testing.T, handle func(conn net.Conn)) uint16 {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return uint16(l.Addr().(*net.TCPAddr).Port)
+}
+
+// Answers a status ping.
+func handleStatus(conn net.Conn) {
+	// Skip handshake & request
+	for i := 0; i < 2; i++ {
+		length, err := enc.ReadVarInt(conn)
+		if err != nil {
+			return
+		}
+		if _, err = io.CopyN(io.Discard, conn, int64(length)); err != nil {
+			return
+		}
+	}
+
+	var body bytes.Buffer
+	_ = enc.WriteVarInt(&body, 0x00)
+	_ = enc.WriteString(&body, enc.String(statusJson))
+
+	var reply bytes.Buffer
+	_ = enc.WriteVarInt(&reply, enc.VarInt(body.Len()))
+	reply.Write(body.Bytes())
+
+	_, _ = conn.Write(reply.Bytes())
+}
+
+// Answers only the legacy ping, like a pre-1.7 server.
+func handleLegacy(conn net.Conn) {
+	first := make([]byte, 1)
+
+	if _, err := io.ReadFull(conn, first); err != nil || first[0] != 0xFE {
+		return
+	}
+
+	chars := utf16.Encode([]rune("§1\x0074\x001.6.4\x00An Old Server\x001\x0010"))
+
+	kick := []byte{0xFF, byte(len(chars) >> 8), byte(len(chars))}
+
+	for _, c := range chars {
+		kick = append(kick, byte(c>>8), byte(c))
+	}
+
+	_, _ = conn.Write(kick)
+}
+
+// Starts a loopback Bedrock server, returning its port.
+func serveBedrock(t *testing.T) uint16 {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	status := "MCPE;Bedrock Server;685;1.21.0;2;10;1;Bedrock level;Survival;1;19132;19133;"
+
+	go func() {
+		buf := make([]byte, 1500)
+
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+
+			if err != nil {
+				return
+			}
+
+			if n != 33 || buf[0] != 0x01 {
+				continue
+			}
+
+			pong := []byte{0x1C}
+			pong = append(pong, buf[1:9]...)
+			pong = append(pong, make([]byte, 8)...) // Server GUID
+			pong = append(pong, raknetMagic...)
+			pong = append(pong, byte(len(status)>>8), byte(len(status)))
+			pong = append(pong, status...)
+
+			_, _ = conn.WriteTo(pong, addr)
+		}
+	}()
+
+	return uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+// Returns a port nothing listens on.
+func closedPort(t *testing.T) uint16 {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := uint16(l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+
+	return port
+}
+
+func collect(t *testing.T, s *Scanner) map[uint16]Result {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	results, err := s.Run(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[uint16]Result)
+
+	for res := range results {
+		found[res.Port] = res
+	}
+
+	return found
+}
+
+func TestScanner(t *testing.T) {
+	status := serve(t, handleStatus)
+	legacy := serve(t, handleLegacy)
+	other := serve(t, func(conn net.Conn) {})
+	closed := closedPort(t)
+	bedrock := serveBedrock(t)
+
+	s := &Scanner{
+		Ranges:         []string{"127.0.0.1"},
+		Ports:          []uint16{status, legacy, other, closed},
+		Client:         mcpinger.NewClient(mcpinger.WithTimeout(time.Second), mcpinger.WithLimiter(&mcpinger.Limiter{MaxPerHost: 2})),
+		Concurrency:    4,
+		Legacy:         true,
+		Bedrock:        true,
+		BedrockPorts:   []uint16{bedrock},
+		BedrockTimeout: time.Second,
+	}
+
+	found := collect(t, s)
+
+	tests := []struct {
+		Port    uint16
+		Edition string
+		Version string
+	}{
+		{status, EditionJava, "1.13.2"},
+		{legacy, EditionJavaLegacy, "1.6.4"},
+		{other, "", ""},
+		{bedrock, EditionBedrock, "1.21.0"},
+	}
+
+	for _, test := range tests {
+		res, ok := found[test.Port]
+
+		if !ok {
+			t.Errorf("Expected a result for port %d", test.Port)
+			continue
+		}
+
+		if res.IP != "127.0.0.1" || res.Edition != test.Edition || res.Version != test.Version {
+			t.Errorf("Unexpected result for port %d: %+v", test.Port, res)
+		}
+
+		if test.Edition == "" && res.Error == "" {
+			t.Errorf("Expected an error for non-Minecraft port %d", test.Port)
+		}
+	}
+
+	if res, ok := found[closed]; ok {
+		t.Errorf("Expected no result for closed port, got %+v", res)
+	}
+
+	if len(found) != len(tests) {
+		t.Errorf("Expected %d results, got %d", len(tests), len(found))
+	}
+}
+
+func TestScannerExclude(t *testing.T) {
+	status := serve(t, handleStatus)
+
+	s := &Scanner{
+		Ranges:  []string{"127.0.0.0/30"},
+		Exclude: []string{"127.0.0.1"},
+		Ports:   []uint16{status},
+		Client:  mcpinger.NewClient(mcpinger.WithTimeout(time.Second)),
+	}
+
+	if found := collect(t, s); len(found) != 0 {
+		t.Errorf("Expected excluded address not to be scanned, got %+v", found)
+	}
+}
+
+func TestScannerInvalid(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Scanner *Scanner
+	}{
+		{"invalid IP", &Scanner{Ranges: []string{"not an ip"}}},
+		{"invalid CIDR", &Scanner{Ranges: []string{"10.0.0.0/33"}}},
+		{"invalid exclusion", &Scanner{Ranges: []string{"10.0.0.0/8"}, Exclude: []string{"10.0.0.0/-1"}}},
+		{"too many addresses", &Scanner{Ranges: []string{"2001:db8::/64"}}},
+		{"over maximum", &Scanner{Ranges: []string{"10.0.0.0/24"}, MaxAddresses: 255}},
+	}
+
+	for _, test := range tests {
+		if _, err := test.Scanner.Run(context.Background()); err == nil {
+			t.Errorf("%s: expected an error", test.Name)
+		}
+	}
+}
+
+func TestNextIP(t *testing.T) {
+	tests := []struct {
+		IP       string
+		Expected string
+	}{
+		{"10.0.0.1", "10.0.0.2"},
+		{"10.0.0.255", "10.0.1.0"},
+		{"2001:db8::ffff", "2001:db8::1:0"},
+	}
+
+	for _, test := range tests {
+		ip := net.ParseIP(test.IP)
+
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+
+		if actual := nextIP(ip).String(); actual != test.Expected {
+			t.Errorf("Expected %s after %s, got %s", test.Expected, test.IP, actual)
+		}
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	results := make(chan Result, 2)
+	results <- Result{IP: "127.0.0.1", Port: 25565, Edition: EditionJava, Online: 3, Max: 20}
+	results <- Result{IP: "127.0.0.1", Port: 8080, Error: "unexpected EOF"}
+	close(results)
+
+	var b bytes.Buffer
+
+	n, err := WriteJSONL(&b, results)
+
+	if err != nil || n != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %v", n, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+
+	var res Result
+
+	if err = json.Unmarshal([]byte(lines[1]), &res); err != nil || res.Port != 8080 || res.Error != "unexpected EOF" {
+		t.Errorf("Unexpected line %q: %v", lines[1], err)
+	}
+}
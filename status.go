@@ -1,9 +1,15 @@
 package mcpinger
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"strings"
 )
 
+// ErrNoFavicon is returned when a server has no PNG favicon.
+var ErrNoFavicon = errors.New("server has no favicon")
+
 // Server info version
 type Version struct {
 	Name     string `json:"name"`     // Version name
@@ -30,11 +36,69 @@ type ServerInfo struct {
 	Players     Players       `json:"players"`     // Server player info
 	Description ChatComponent `json:"description"` // Server description
 	Favicon     string        `json:"favicon"`     // Server favicon
+
+	EnforcesSecureChat  *bool      `json:"enforcesSecureChat,omitempty"`  // Whether chat messages must be signed, nil when not sent
+	PreventsChatReports *bool      `json:"preventsChatReports,omitempty"` // Sent by No Chat Reports and similar mods & plugins
+	IsModded            bool       `json:"isModded,omitempty"`            // Sent by NeoForge servers
+	ForgeData           *ForgeData `json:"forgeData,omitempty"`           // Sent by Forge 1.13+ servers
+	ModInfo             *ModInfo   `json:"modinfo,omitempty"`             // Sent by Forge 1.7-1.12 servers & BungeeCord
+
+	Raw json.RawMessage `json:"-"` // Raw JSON of the response
+}
+
+// Forge server data
+// https://wiki.vg/Server_List_Ping#Forge
+type ForgeData struct {
+	Channels          []ForgeChannel `json:"channels"`
+	Mods              []ForgeMod     `json:"mods"`
+	FMLNetworkVersion int32          `json:"fmlNetworkVersion"`
+	Truncated         bool           `json:"truncated,omitempty"` // Whether channels & mods were left out
+	Data              string         `json:"d,omitempty"`         // Compressed channels & mods, sent by Forge 1.18.1+
+}
+
+// Forge network channel
+type ForgeChannel struct {
+	Res      string `json:"res"`
+	Version  string `json:"version"`
+	Required bool   `json:"required"`
+}
+
+// Forge mod
+type ForgeMod struct {
+	ModID  string `json:"modId"`
+	Marker string `json:"modmarker"` // Mod version
+}
+
+// Legacy Forge mod info
+type ModInfo struct {
+	Type    string `json:"type"` // "FML" for Forge
+	ModList []Mod  `json:"modList"`
+}
+
+// Legacy Forge mod
+type Mod struct {
+	ModID   string `json:"modid"`
+	Version string `json:"version"`
 }
 
 // Parses the provided json byte array into a ServerInfo struct
 func parseServerInfo(infoJson []byte) (*ServerInfo, error) {
 	info := new(ServerInfo)
 	err := json.Unmarshal(infoJson, info)
+	info.Raw = json.RawMessage(infoJson)
 	return info, err
 }
+
+// FaviconPNG decodes the favicon data URI into PNG image data.
+func (i *ServerInfo) FaviconPNG() ([]byte, error) {
+	const prefix = "data:image/png;base64,"
+
+	if !strings.HasPrefix(i.Favicon, prefix) {
+		return nil, ErrNoFavicon
+	}
+
+	// Older servers wrap the base64 data in lines
+	data := strings.NewReplacer("\n", "", "\r", "").Replace(i.Favicon[len(prefix):])
+
+	return base64.StdEncoding.DecodeString(data)
+}
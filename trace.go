@@ -0,0 +1,204 @@
+package mcpinger
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// PingTrace is a set of hooks called at each stage of a ping or login probe,
+// similar to httptrace.ClientTrace. Any hook may be nil.
+// Hooks may be called concurrently when multiple addresses are dialed at once.
+type PingTrace struct {
+	// DNSStart is called before looking up the IP addresses of host.
+	DNSStart func(host string)
+
+	// DNSDone is called after looking up the IP addresses.
+	DNSDone func(ips []net.IP, err error)
+
+	// SRVResolved is called after looking up the SRV record of the host,
+	// with the target the server is reached at.
+	SRVResolved func(target string, port uint16, err error)
+
+	// ConnectStart is called before connecting to addr.
+	ConnectStart func(network, addr string)
+
+	// ConnectDone is called after connecting to addr.
+	ConnectDone func(network, addr string, err error)
+
+	// ProxyHeaderWritten is called after writing the PROXY header.
+	ProxyHeaderWritten func(err error)
+
+	// HandshakeSent is called after sending the handshake and the
+	// status request or login start.
+	HandshakeSent func(err error)
+
+	// ResponseHeaderRead is called after reading the header of the response.
+	ResponseHeaderRead func(length int32, packetID int32)
+
+	// ResponseParsed is called after parsing the status response.
+	ResponseParsed func(info *ServerInfo, err error)
+
+	// PongReceived is called after the ping/pong exchange.
+	PongReceived func(rtt time.Duration, err error)
+}
+
+type pingTraceKey struct{}
+
+// WithPingTrace returns a context based on ctx which calls the hooks of trace.
+// Hooks of a trace already in ctx are called after the hooks of trace.
+func WithPingTrace(ctx context.Context, trace *PingTrace) context.Context {
+	if trace == nil {
+		panic("nil trace")
+	}
+
+	if old := ContextPingTrace(ctx); old != nil {
+		trace = trace.compose(old)
+	}
+
+	return context.WithValue(ctx, pingTraceKey{}, trace)
+}
+
+// ContextPingTrace returns the PingTrace associated with ctx, if any.
+func ContextPingTrace(ctx context.Context) *PingTrace {
+	trace, _ := ctx.Value(pingTraceKey{}).(*PingTrace)
+	return trace
+}
+
+// Returns a trace calling the hooks of t, followed by those of old.
+func (t *PingTrace) compose(old *PingTrace) *PingTrace {
+	c := *t
+
+	if c.DNSStart == nil {
+		c.DNSStart = old.DNSStart
+	} else if old.DNSStart != nil {
+		c.DNSStart = func(host string) { t.DNSStart(host); old.DNSStart(host) }
+	}
+
+	if c.DNSDone == nil {
+		c.DNSDone = old.DNSDone
+	} else if old.DNSDone != nil {
+		c.DNSDone = func(ips []net.IP, err error) { t.DNSDone(ips, err); old.DNSDone(ips, err) }
+	}
+
+	if c.SRVResolved == nil {
+		c.SRVResolved = old.SRVResolved
+	} else if old.SRVResolved != nil {
+		c.SRVResolved = func(target string, port uint16, err error) {
+			t.SRVResolved(target, port, err)
+			old.SRVResolved(target, port, err)
+		}
+	}
+
+	if c.ConnectStart == nil {
+		c.ConnectStart = old.ConnectStart
+	} else if old.ConnectStart != nil {
+		c.ConnectStart = func(network, addr string) { t.ConnectStart(network, addr); old.ConnectStart(network, addr) }
+	}
+
+	if c.ConnectDone == nil {
+		c.ConnectDone = old.ConnectDone
+	} else if old.ConnectDone != nil {
+		c.ConnectDone = func(network, addr string, err error) {
+			t.ConnectDone(network, addr, err)
+			old.ConnectDone(network, addr, err)
+		}
+	}
+
+	if c.ProxyHeaderWritten == nil {
+		c.ProxyHeaderWritten = old.ProxyHeaderWritten
+	} else if old.ProxyHeaderWritten != nil {
+		c.ProxyHeaderWritten = func(err error) { t.ProxyHeaderWritten(err); old.ProxyHeaderWritten(err) }
+	}
+
+	if c.HandshakeSent == nil {
+		c.HandshakeSent = old.HandshakeSent
+	} else if old.HandshakeSent != nil {
+		c.HandshakeSent = func(err error) { t.HandshakeSent(err); old.HandshakeSent(err) }
+	}
+
+	if c.ResponseHeaderRead == nil {
+		c.ResponseHeaderRead = old.ResponseHeaderRead
+	} else if old.ResponseHeaderRead != nil {
+		c.ResponseHeaderRead = func(length int32, packetID int32) {
+			t.ResponseHeaderRead(length, packetID)
+			old.ResponseHeaderRead(length, packetID)
+		}
+	}
+
+	if c.ResponseParsed == nil {
+		c.ResponseParsed = old.ResponseParsed
+	} else if old.ResponseParsed != nil {
+		c.ResponseParsed = func(info *ServerInfo, err error) { t.ResponseParsed(info, err); old.ResponseParsed(info, err) }
+	}
+
+	if c.PongReceived == nil {
+		c.PongReceived = old.PongReceived
+	} else if old.PongReceived != nil {
+		c.PongReceived = func(rtt time.Duration, err error) { t.PongReceived(rtt, err); old.PongReceived(rtt, err) }
+	}
+
+	return &c
+}
+
+// Nil-safe helpers calling the hooks.
+
+func (t *PingTrace) dnsStart(host string) {
+	if t != nil && t.DNSStart != nil {
+		t.DNSStart(host)
+	}
+}
+
+func (t *PingTrace) dnsDone(ips []net.IP, err error) {
+	if t != nil && t.DNSDone != nil {
+		t.DNSDone(ips, err)
+	}
+}
+
+func (t *PingTrace) srvResolved(target string, port uint16, err error) {
+	if t != nil && t.SRVResolved != nil {
+		t.SRVResolved(target, port, err)
+	}
+}
+
+func (t *PingTrace) connectStart(network, addr string) {
+	if t != nil && t.ConnectStart != nil {
+		t.ConnectStart(network, addr)
+	}
+}
+
+func (t *PingTrace) connectDone(network, addr string, err error) {
+	if t != nil && t.ConnectDone != nil {
+		t.ConnectDone(network, addr, err)
+	}
+}
+
+func (t *PingTrace) proxyHeaderWritten(err error) {
+	if t != nil && t.ProxyHeaderWritten != nil {
+		t.ProxyHeaderWritten(err)
+	}
+}
+
+func (t *PingTrace) handshakeSent(err error) {
+	if t != nil && t.HandshakeSent != nil {
+		t.HandshakeSent(err)
+	}
+}
+
+func (t *PingTrace) responseHeaderRead(length int32, packetID int32) {
+	if t != nil && t.ResponseHeaderRead != nil {
+		t.ResponseHeaderRead(length, packetID)
+	}
+}
+
+func (t *PingTrace) responseParsed(info *ServerInfo, err error) {
+	if t != nil && t.ResponseParsed != nil {
+		t.ResponseParsed(info, err)
+	}
+}
+
+func (t *PingTrace) pongReceived(rtt time.Duration, err error) {
+	if t != nil && t.PongReceived != nil {
+		t.PongReceived(rtt, err)
+	}
+}
@@ -0,0 +1,84 @@
+package mcpinger
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPingTrace(t *testing.T) {
+	host, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	var mu sync.Mutex
+	var stages []string
+
+	record := func(stage string) {
+		mu.Lock()
+		defer mu.Unlock()
+		stages = append(stages, stage)
+	}
+
+	ctx := WithPingTrace(context.Background(), &PingTrace{
+		DNSStart:     func(host string) { record("DNSStart") },
+		ConnectStart: func(network, addr string) { record("ConnectStart") },
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil {
+				t.Error(err)
+			}
+			record("ConnectDone")
+		},
+		HandshakeSent: func(err error) { record("HandshakeSent") },
+		ResponseHeaderRead: func(length int32, packetID int32) {
+			if length <= 0 || packetID != 0 {
+				t.Errorf("Unexpected response header: length %d, id %d", length, packetID)
+			}
+			record("ResponseHeaderRead")
+		},
+		ResponseParsed: func(info *ServerInfo, err error) { record("ResponseParsed") },
+		PongReceived:   func(rtt time.Duration, err error) { record("PongReceived") },
+	})
+
+	// Hooks of an outer trace are still called
+	ctx = WithPingTrace(ctx, &PingTrace{
+		ResponseParsed: func(info *ServerInfo, err error) { record("ResponseParsed (outer)") },
+	})
+
+	if _, err := NewClient(WithLatency(time.Second)).Ping(ctx, host, port); err != nil {
+		t.Fatal(err)
+	}
+
+	// No DNS lookup happens for IP addresses
+	expected := []string{
+		"ConnectStart",
+		"ConnectDone",
+		"HandshakeSent",
+		"ResponseHeaderRead",
+		"ResponseParsed (outer)",
+		"ResponseParsed",
+		"PongReceived",
+	}
+
+	if !reflect.DeepEqual(stages, expected) {
+		t.Errorf("Unexpected stages: %v", stages)
+	}
+}
+
+func TestPingTraceDNS(t *testing.T) {
+	var ips []net.IP
+
+	ctx := WithPingTrace(context.Background(), &PingTrace{
+		DNSDone: func(addrs []net.IP, err error) { ips = addrs },
+	})
+
+	// localhost resolves without a DNS server
+	_, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	_, _ = NewClient(WithTimeout(time.Second)).Ping(ctx, "localhost", port)
+
+	if len(ips) == 0 {
+		t.Error("Expected DNSDone to be called with the addresses of localhost")
+	}
+}
@@ -0,0 +1,230 @@
+package mcpinger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultMaxTranslationDepth is the nesting of translations resolved when a Translator has no MaxDepth.
+	DefaultMaxTranslationDepth = 8
+
+	// DefaultMaxTranslationComponents is the amount of components resolved when a Translator has no MaxComponents.
+	DefaultMaxTranslationComponents = 4096
+)
+
+// Translator resolves translate components with a language file,
+// like the en_us.json shipped with the client.
+type Translator struct {
+	Language map[string]string // Translations by key
+	MaxDepth int               // Nesting of translations in arguments resolved, DefaultMaxTranslationDepth when zero
+
+	// MaxComponents is the amount of components resolved by a single Resolve, as arguments
+	// may be substituted many times each. DefaultMaxTranslationComponents when zero.
+	MaxComponents int
+}
+
+// NewTranslator creates a translator of a language.
+func NewTranslator(language map[string]string) *Translator {
+	return &Translator{Language: language}
+}
+
+// LoadTranslator reads a language file, a JSON object of translations by key.
+func LoadTranslator(r io.Reader) (*Translator, error) {
+	language := make(map[string]string)
+
+	if err := json.NewDecoder(r).Decode(&language); err != nil {
+		return nil, errors.New("could not read language file: " + err.Error())
+	}
+
+	return NewTranslator(language), nil
+}
+
+// LoadTranslatorFile reads a language file from disk, see LoadTranslator.
+func LoadTranslatorFile(path string) (*Translator, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, errors.New("could not open language file: " + err.Error())
+	}
+
+	defer f.Close()
+
+	return LoadTranslator(f)
+}
+
+// Translate formats the translation of a key with arguments substituted for
+// %s & %1$s placeholders. Unknown keys are returned as is.
+func (t *Translator) Translate(key string, args ...string) string {
+	format, ok := t.Language[key]
+
+	if !ok {
+		return key
+	}
+
+	var b strings.Builder
+
+	for _, part := range parseTranslation(format) {
+		if part.arg < 0 {
+			b.WriteString(part.text)
+		} else if part.arg < len(args) {
+			b.WriteString(args[part.arg])
+		}
+	}
+
+	return b.String()
+}
+
+// Resolve replaces translate components within the component with their translation,
+// keeping the style of the component for its arguments. Keys missing from the language
+// use the fallback of the component, or the key itself. Translations nested deeper
+// than MaxDepth are left as their fallback or key, and arguments are no longer
+// substituted once MaxComponents components were resolved.
+func (t *Translator) Resolve(c ChatComponent) ChatComponent {
+	r := &resolution{maxDepth: t.MaxDepth, budget: t.MaxComponents}
+
+	if r.maxDepth <= 0 {
+		r.maxDepth = DefaultMaxTranslationDepth
+	}
+
+	if r.budget <= 0 {
+		r.budget = DefaultMaxTranslationComponents
+	}
+
+	return t.resolve(c, 0, r)
+}
+
+// Limits of a single Resolve
+type resolution struct {
+	maxDepth int
+	budget   int // Components left to resolve
+}
+
+func (t *Translator) resolve(c ChatComponent, depth int, r *resolution) ChatComponent {
+	var extra []ChatComponent
+
+	r.budget--
+
+	if c.Translate != "" {
+		extra = t.translate(c.RegularChatComponent, depth, r)
+	}
+
+	for _, sibling := range c.Extra {
+		extra = append(extra, t.resolve(sibling, depth, r))
+	}
+
+	c.Extra = extra
+	c.Translate, c.With, c.Fallback = "", nil, ""
+
+	return c
+}
+
+// Returns the components of a translation, to be prepended to the siblings of the component.
+func (t *Translator) translate(c RegularChatComponent, depth int, r *resolution) []ChatComponent {
+	untranslated := c.Fallback
+
+	if untranslated == "" {
+		untranslated = c.Translate
+	}
+
+	if depth >= r.maxDepth {
+		return []ChatComponent{textComponent(untranslated)}
+	}
+
+	format, ok := t.Language[c.Translate]
+
+	if !ok {
+		format = untranslated
+	}
+
+	var components []ChatComponent
+
+	for _, part := range parseTranslation(format) {
+		switch {
+		case part.arg < 0:
+			components = append(components, textComponent(part.text))
+		case part.arg < len(c.With) && r.budget > 0:
+			components = append(components, t.resolve(c.With[part.arg], depth+1, r))
+		}
+	}
+
+	return components
+}
+
+// Returns a component of unstyled text.
+func textComponent(text string) ChatComponent {
+	var c ChatComponent
+	c.Text = text
+	return c
+}
+
+// Literal text of a translation, or a placeholder of the argument with index arg
+type translationPart struct {
+	text string
+	arg  int // Index of the argument, -1 for text
+}
+
+// Splits a translation into text & placeholders. %% is a literal percent sign,
+// invalid placeholders are kept as text.
+func parseTranslation(format string) []translationPart {
+	var parts []translationPart
+	var text strings.Builder
+	next := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			text.WriteByte(format[i])
+			continue
+		}
+
+		arg := -1
+		end := i + 1
+
+		switch {
+		case format[end] == '%':
+			text.WriteByte('%')
+			i = end
+			continue
+		case format[end] == 's':
+			arg = next
+			next++
+		default:
+			// Positional %1$s
+			digits := end
+
+			for digits < len(format) && format[digits] >= '0' && format[digits] <= '9' {
+				digits++
+			}
+
+			if digits > end && digits+1 < len(format) && format[digits] == '$' && format[digits+1] == 's' {
+				if n, err := strconv.Atoi(format[end:digits]); err == nil && n > 0 {
+					arg = n - 1
+					end = digits + 1
+				}
+			}
+		}
+
+		if arg < 0 {
+			text.WriteByte(format[i])
+			continue
+		}
+
+		if text.Len() > 0 {
+			parts = append(parts, translationPart{text: text.String(), arg: -1})
+			text.Reset()
+		}
+
+		parts = append(parts, translationPart{arg: arg})
+		i = end
+	}
+
+	if text.Len() > 0 {
+		parts = append(parts, translationPart{text: text.String(), arg: -1})
+	}
+
+	return parts
+}
@@ -0,0 +1,141 @@
+package mcpinger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var testLanguage = `{
+	"multiplayer.disconnect.outdated_client": "Incompatible client! Please use %s",
+	"multiplayer.disconnect.banned.reason": "You are banned from this server.\nReason: %s",
+	"chat.type.text": "<%s> %s",
+	"test.positional": "%2$s before %1$s, 100%%",
+	"test.nested": "[%s]"
+}`
+
+func TestTranslatorResolve(t *testing.T) {
+	translator, err := LoadTranslator(strings.NewReader(testLanguage))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Json     string
+		Expected string
+	}{
+		{`{"translate":"multiplayer.disconnect.outdated_client","with":["1.20.4"]}`, "Incompatible client! Please use 1.20.4"},
+		{`{"translate":"chat.type.text","with":[{"text":"Steve","color":"gold"},"Hi"]}`, "<Steve> Hi"},
+		{`{"translate":"test.positional","with":["a",2]}`, "2 before a, 100%"},
+		{`{"translate":"unknown.key","fallback":"Fallback %s","with":["text"]}`, "Fallback text"},
+		{`{"translate":"unknown.key"}`, "unknown.key"},
+		{`{"text":"Prefix: ","extra":[{"translate":"test.nested","with":["x"]}]}`, "Prefix: [x]"},
+		{`{"translate":"chat.type.text","with":["only one"]}`, "<only one> "},
+		{`"plain"`, "plain"},
+	}
+
+	for _, test := range tests {
+		var c ChatComponent
+
+		if err := json.Unmarshal([]byte(test.Json), &c); err != nil {
+			t.Fatalf("Unable to parse %s: %v", test.Json, err)
+		}
+
+		if actual := translator.Resolve(c).PlainText(); actual != test.Expected {
+			t.Errorf("Unexpected translation of %s, expected %q, got %q", test.Json, test.Expected, actual)
+		}
+	}
+}
+
+func TestTranslatorResolveStyle(t *testing.T) {
+	translator := NewTranslator(map[string]string{"chat.type.text": "<%s> %s"})
+
+	var c ChatComponent
+	if err := json.Unmarshal([]byte(`{"translate":"chat.type.text","color":"red","with":[{"text":"Steve","color":"gold"},"Hi"]}`), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, span := range translator.Resolve(c).Spans() {
+		expected := "red"
+
+		if span.Text == "Steve" {
+			expected = "gold"
+		}
+
+		if span.Color != expected {
+			t.Errorf("Expected %q to be %s, got %q", span.Text, expected, span.Color)
+		}
+	}
+}
+
+func TestTranslatorMaxDepth(t *testing.T) {
+	translator := NewTranslator(map[string]string{"test.nested": "[%s]"})
+	translator.MaxDepth = 2
+
+	// Deeply nested arguments stop at their key
+	c := textComponent("end")
+
+	for i := 0; i < 5; i++ {
+		var parent ChatComponent
+		parent.Translate = "test.nested"
+		parent.With = []ChatComponent{c}
+		c = parent
+	}
+
+	if actual := translator.Resolve(c).PlainText(); actual != "[[test.nested]]" {
+		t.Errorf("Unexpected translation, got %q", actual)
+	}
+}
+
+func TestTranslatorMaxComponents(t *testing.T) {
+	translator := NewTranslator(map[string]string{"test.repeat": strings.Repeat("%1$s", 10)})
+
+	// Every level repeats its argument 10 times, 10^8 times in total
+	c := textComponent("x")
+
+	for i := 0; i < DefaultMaxTranslationDepth; i++ {
+		var parent ChatComponent
+		parent.Translate = "test.repeat"
+		parent.With = []ChatComponent{c}
+		c = parent
+	}
+
+	if n := len(translator.Resolve(c).PlainText()); n == 0 || n > DefaultMaxTranslationComponents {
+		t.Errorf("Expected the substitutions to stop within the budget, got %d characters", n)
+	}
+}
+
+func TestUntranslatedSpans(t *testing.T) {
+	tests := []struct {
+		Json     string
+		Expected string
+	}{
+		{`{"translate":"multiplayer.disconnect.outdated_client","with":["1.20.4"]}`, "multiplayer.disconnect.outdated_client"},
+		{`{"translate":"unknown.key","fallback":"Fallback"}`, "Fallback"},
+	}
+
+	for _, test := range tests {
+		var c ChatComponent
+
+		if err := json.Unmarshal([]byte(test.Json), &c); err != nil {
+			t.Fatalf("Unable to parse %s: %v", test.Json, err)
+		}
+
+		if actual := c.PlainText(); actual != test.Expected {
+			t.Errorf("Unexpected text of %s, expected %q, got %q", test.Json, test.Expected, actual)
+		}
+	}
+}
+
+func TestTranslatorTranslate(t *testing.T) {
+	translator := NewTranslator(map[string]string{"test.positional": "%2$s before %1$s, 100%%"})
+
+	if actual := translator.Translate("test.positional", "a", "b"); actual != "b before a, 100%" {
+		t.Errorf("Unexpected translation, got %q", actual)
+	}
+
+	if actual := translator.Translate("unknown.key", "a"); actual != "unknown.key" {
+		t.Errorf("Expected an unknown key to be returned as is, got %q", actual)
+	}
+}
@@ -0,0 +1,278 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of change a Watcher noticed.
+type EventType int
+
+const (
+	WentOnline     EventType = iota + 1 // Server answered after being offline or unknown
+	WentOffline                         // Server stopped answering
+	VersionChanged                      // Version name or protocol changed
+	MOTDChanged                         // Description changed
+	PlayerSeen                          // Player appeared in the player sample
+	PlayerGone                          // Player disappeared from the player sample
+	FaviconChanged                      // Favicon changed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case WentOnline:
+		return "went online"
+	case WentOffline:
+		return "went offline"
+	case VersionChanged:
+		return "version changed"
+	case MOTDChanged:
+		return "MOTD changed"
+	case PlayerSeen:
+		return "player seen"
+	case PlayerGone:
+		return "player gone"
+	case FaviconChanged:
+		return "favicon changed"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event is a change of a watched server.
+type Event struct {
+	Type EventType
+	Time time.Time // Time of the ping which noticed the change
+
+	Host string
+	Port uint16
+
+	Previous *ServerInfo // Last known server info, nil when unknown
+	Current  *ServerInfo // Current server info, nil when offline
+	Player   Player      // Player which was seen or is gone
+	Err      error       // Error of the last ping when offline
+}
+
+// Watcher pings a server on an interval and emits an Event for every change.
+// Servers with more than 12 players rotate the player sample, so PlayerSeen &
+// PlayerGone only reflect the sample, see PresenceTracker for aggregating it.
+type Watcher struct {
+	Client   *Client       // Client to ping with, a default Client when nil
+	Host     string        // Host of the server to watch
+	Port     uint16        // Port of the server to watch
+	Interval time.Duration // Interval between pings
+	Jitter   float64       // Fraction of the interval which is randomized, between 0 and 1
+
+	// Consecutive successful pings needed before going online, and failed
+	// pings needed before going offline, damping flapping servers.
+	// Zero values are treated as 1.
+	OnlineThreshold  int
+	OfflineThreshold int
+
+	// OnResult is called with the result of every ping, if set.
+	OnResult func(res *Result, err error)
+
+	events     chan Event
+	eventsOnce sync.Once
+
+	// Watch state
+	online    bool
+	known     bool // Whether online is known
+	successes int
+	failures  int
+	last      *ServerInfo // Last info while online
+}
+
+// NewWatcher Creates a new Watcher pinging the server with the client every interval.
+func NewWatcher(client *Client, host string, port uint16, interval time.Duration) *Watcher {
+	return &Watcher{
+		Client:   client,
+		Host:     host,
+		Port:     port,
+		Interval: interval,
+	}
+}
+
+// Events returns the channel events are emitted on,
+// which is closed when Run returns.
+func (w *Watcher) Events() <-chan Event {
+	w.eventsOnce.Do(func() {
+		w.events = make(chan Event, 16)
+	})
+	return w.events
+}
+
+// Run pings the server until ctx is done, emitting events on the Events
+// channel. A Watcher can only be run once.
+func (w *Watcher) Run(ctx context.Context) error {
+	w.Events()
+	defer close(w.events)
+
+	if w.Interval <= 0 {
+		return errors.New("watch interval must be positive")
+	}
+
+	client := w.Client
+
+	if client == nil {
+		client = NewClient()
+	}
+
+	for {
+		now := time.Now()
+		res, err := client.Query(ctx, w.Host, w.Port)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if w.OnResult != nil {
+			w.OnResult(res, err)
+		}
+
+		for _, event := range w.observe(res, err, now) {
+			select {
+			case w.events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		timer := time.NewTimer(w.nextInterval())
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Returns the interval until the next ping, with jitter applied.
+func (w *Watcher) nextInterval() time.Duration {
+	if w.Jitter <= 0 {
+		return w.Interval
+	}
+
+	// Spread evenly around the interval, never below zero
+	jitter := math.Min(w.Jitter, 1)
+	offset := (randFloat64()*2 - 1) * jitter * float64(w.Interval)
+
+	return w.Interval + time.Duration(offset)
+}
+
+// Updates the watch state with the result of a ping,
+// returning the events it caused.
+func (w *Watcher) observe(res *Result, err error, now time.Time) []Event {
+	if err != nil {
+		w.successes = 0
+		w.failures++
+
+		if (w.online || !w.known) && w.failures >= threshold(w.OfflineThreshold) {
+			w.online, w.known = false, true
+			event := w.event(WentOffline, now, nil, err)
+
+			// Changes during the outage aren't reported when coming back online
+			w.last = nil
+
+			return []Event{event}
+		}
+
+		return nil
+	}
+
+	w.failures = 0
+	w.successes++
+
+	info := res.Info
+	var events []Event
+
+	if !w.online {
+		if w.successes < threshold(w.OnlineThreshold) {
+			return nil
+		}
+
+		w.online, w.known = true, true
+		events = append(events, w.event(WentOnline, now, info, nil))
+	}
+
+	if w.last != nil {
+		events = append(events, w.diff(w.last, info, now)...)
+	}
+
+	w.last = info
+
+	return events
+}
+
+// Returns the events for the changes between two infos.
+func (w *Watcher) diff(prev, cur *ServerInfo, now time.Time) []Event {
+	var events []Event
+
+	if prev.Version != cur.Version {
+		events = append(events, w.event(VersionChanged, now, cur, nil))
+	}
+
+	if !reflect.DeepEqual(prev.Description, cur.Description) {
+		events = append(events, w.event(MOTDChanged, now, cur, nil))
+	}
+
+	if prev.Favicon != cur.Favicon {
+		events = append(events, w.event(FaviconChanged, now, cur, nil))
+	}
+
+	prevPlayers := samplePlayers(prev)
+	curPlayers := samplePlayers(cur)
+
+	for _, player := range cur.Players.Sample {
+		if _, ok := prevPlayers[player.ID]; !ok {
+			event := w.event(PlayerSeen, now, cur, nil)
+			event.Player = player
+			events = append(events, event)
+		}
+	}
+
+	for _, player := range prev.Players.Sample {
+		if _, ok := curPlayers[player.ID]; !ok {
+			event := w.event(PlayerGone, now, cur, nil)
+			event.Player = player
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+func (w *Watcher) event(t EventType, now time.Time, cur *ServerInfo, err error) Event {
+	return Event{
+		Type:     t,
+		Time:     now,
+		Host:     w.Host,
+		Port:     w.Port,
+		Previous: w.last,
+		Current:  cur,
+		Err:      err,
+	}
+}
+
+// Returns the players in the sample of info by ID.
+func samplePlayers(info *ServerInfo) map[string]Player {
+	players := make(map[string]Player, len(info.Players.Sample))
+	for _, player := range info.Players.Sample {
+		players[player.ID] = player
+	}
+	return players
+}
+
+func threshold(t int) int {
+	if t <= 0 {
+		return 1
+	}
+	return t
+}
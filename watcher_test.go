@@ -0,0 +1,162 @@
+package mcpinger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWatcherObserve(t *testing.T) {
+	w := &Watcher{OnlineThreshold: 2, OfflineThreshold: 2}
+
+	down := errors.New("connection refused")
+	now := time.Now()
+
+	steve := Player{Name: "Steve", ID: "069a79f4-44e9-4726-a5be-fca90e38aaf5"}
+	alex := Player{Name: "Alex", ID: "ec561538-f3fd-461d-aff5-086b22154bce"}
+
+	first := &ServerInfo{Version: Version{Name: "1.20.4", Protocol: 765}}
+	first.Players.Sample = []Player{steve}
+
+	second := &ServerInfo{Version: Version{Name: "1.21", Protocol: 767}, Favicon: "data:image/png;base64,AA=="}
+	second.Players.Sample = []Player{alex}
+	second.Description.Text = "Updated!"
+
+	steps := []struct {
+		info     *ServerInfo
+		err      error
+		expected []EventType
+	}{
+		{first, nil, nil}, // Below the online threshold
+		{first, nil, []EventType{WentOnline}},
+		{nil, down, nil},  // Flap, below the offline threshold
+		{first, nil, nil}, // Still online, nothing changed
+		{second, nil, []EventType{VersionChanged, MOTDChanged, FaviconChanged, PlayerSeen, PlayerGone}},
+		{nil, down, nil},
+		{nil, down, []EventType{WentOffline}},
+		{nil, down, nil}, // Already offline
+		{first, nil, nil},
+		{first, nil, []EventType{WentOnline}}, // Not compared to the info from before the outage
+		{second, nil, []EventType{VersionChanged, MOTDChanged, FaviconChanged, PlayerSeen, PlayerGone}},
+	}
+
+	for i, step := range steps {
+		var res *Result
+		if step.info != nil {
+			res = &Result{Info: step.info}
+		}
+
+		events := w.observe(res, step.err, now)
+
+		if len(events) != len(step.expected) {
+			t.Fatalf("Step %d: expected events %v, got %v", i, step.expected, eventTypes(events))
+		}
+
+		for j, event := range events {
+			if event.Type != step.expected[j] {
+				t.Errorf("Step %d: expected events %v, got %v", i, step.expected, eventTypes(events))
+				break
+			}
+		}
+	}
+}
+
+func TestWatcherObserveOfflineFromStart(t *testing.T) {
+	w := &Watcher{}
+
+	events := w.observe(nil, errors.New("connection refused"), time.Now())
+
+	if len(events) != 1 || events[0].Type != WentOffline || events[0].Err == nil {
+		t.Fatalf("Expected a WentOffline event with an error, got %v", eventTypes(events))
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	host, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	w := NewWatcher(NewClient(WithTimeout(time.Second)), host, port, 10*time.Millisecond)
+	w.Jitter = 0.5
+
+	results := 0
+	w.OnResult = func(res *Result, err error) {
+		results++
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	event := <-w.Events()
+
+	if event.Type != WentOnline || event.Current.Version.Name != "1.13.2" {
+		t.Errorf("Expected a WentOnline event, got %s", event.Type)
+	}
+
+	cancel()
+
+	// Drain until the channel is closed
+	for range w.Events() {
+	}
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the watcher to stop with context.Canceled, got %v", err)
+	}
+
+	if results == 0 {
+		t.Error("Expected OnResult to be called")
+	}
+}
+
+func TestWatcherEventsConcurrent(t *testing.T) {
+	host, port := serveStatus(t, GetTestFileContents(t, "info.json"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := &Watcher{Client: NewClient(WithTimeout(time.Second)), Host: host, Port: port, Interval: time.Second}
+
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	if event := <-w.Events(); event.Type != WentOnline {
+		t.Errorf("Expected a WentOnline event, got %s", event.Type)
+	}
+
+	cancel()
+
+	for range w.Events() {
+	}
+
+	<-done
+}
+
+func TestWatcherInterval(t *testing.T) {
+	w := NewWatcher(NewClient(), "127.0.0.1", 25565, 0)
+
+	if err := w.Run(context.Background()); err == nil {
+		t.Error("Expected a zero interval to be refused")
+	}
+
+	if _, ok := <-w.Events(); ok {
+		t.Error("Expected the events channel to be closed")
+	}
+
+	w = &Watcher{Interval: time.Second, Jitter: 5}
+
+	for i := 0; i < 100; i++ {
+		if d := w.nextInterval(); d < 0 || d > 2*time.Second {
+			t.Fatalf("Jittered interval out of range: %s", d)
+		}
+	}
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	return types
+}